@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"reflect"
+	"strings"
+)
+
+// FieldNamer returns the name a struct field is matched by, or an empty name
+// when the field must be ignored.
+type FieldNamer func(field reflect.StructField) string
+
+// NewFieldNamer returns the FieldNamer of strategy. tagKey is the struct tag
+// read by namestrategies.Tag, fields without that tag keep their Go name.
+func NewFieldNamer(strategy namestrategies.NameStrategy, tagKey string) FieldNamer {
+	switch strategy {
+	case namestrategies.JSONTag:
+		return func(field reflect.StructField) string {
+			return tagFieldName(field, "json")
+		}
+	case namestrategies.Tag:
+		return func(field reflect.StructField) string {
+			return tagFieldName(field, tagKey)
+		}
+	case namestrategies.SnakeCase:
+		return func(field reflect.StructField) string {
+			if field.PkgPath != "" {
+				return ""
+			}
+			return ConvertToSnakeCase(field.Name)
+		}
+	default:
+		return GoFieldName
+	}
+}
+
+// GoFieldName names a struct field by its Go name.
+func GoFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	return field.Name
+}
+
+func tagFieldName(field reflect.StructField, tagKey string) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	tag, ok := field.Tag.Lookup(tagKey)
+	if !ok {
+		return field.Name
+	}
+	if tag == "-" {
+		return ""
+	}
+	name := tag
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		name = tag[:i]
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
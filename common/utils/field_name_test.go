@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"reflect"
+	"testing"
+)
+
+func TestNewFieldNamer(t *testing.T) {
+	type account struct {
+		MemberID  string `json:"member_id,omitempty" dv:"member"`
+		Division  string `json:",omitempty"`
+		Secret    string `json:"-" dv:"-"`
+		CreatedAt string
+		internal  string
+	}
+	rType := reflect.TypeOf(account{})
+
+	tests := []struct {
+		name     string
+		strategy namestrategies.NameStrategy
+		tagKey   string
+		want     []string
+	}{
+		{"FieldName", namestrategies.FieldName, "", []string{"MemberID", "Division", "Secret", "CreatedAt", ""}},
+		{"JSONTag", namestrategies.JSONTag, "", []string{"member_id", "Division", "", "CreatedAt", ""}},
+		{"Tag", namestrategies.Tag, "dv", []string{"member", "Division", "", "CreatedAt", ""}},
+		{"SnakeCase", namestrategies.SnakeCase, "", []string{"member_id", "division", "secret", "created_at", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldNamer := NewFieldNamer(tt.strategy, tt.tagKey)
+			for i := 0; i < rType.NumField(); i++ {
+				if got := fieldNamer(rType.Field(i)); got != tt.want[i] {
+					t.Errorf("FieldNamer(%s) = %s; want %s", rType.Field(i).Name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
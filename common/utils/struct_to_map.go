@@ -3,42 +3,78 @@ package utils
 import "reflect"
 
 func StructsToMap(attributeNames map[string]interface{}, data interface{}) map[string]interface{} {
+	return StructsToMapWithNamer(attributeNames, data, GoFieldName)
+}
+
+// StructsToMapWithNamer works like StructsToMap but keys every field by the
+// name fieldNamer gives it. A field hides the fields of the same name found
+// deeper in nested structs, between fields at the same depth the last object
+// wins. A struct reached again through a pointer, e.g. in a cyclic graph, is
+// walked once.
+func StructsToMapWithNamer(attributeNames map[string]interface{}, data interface{}, fieldNamer FieldNamer) map[string]interface{} {
 	result := make(map[string]interface{})
+	structsToMap(attributeNames, data, fieldNamer, 0, result, make(map[string]int), make(map[visit]bool))
+	return result
+}
 
+// visit is a struct reached through a pointer, by its address and type.
+type visit struct {
+	pointer uintptr
+	rType   reflect.Type
+}
+
+func structsToMap(attributeNames map[string]interface{}, data interface{}, fieldNamer FieldNamer, depth int, result map[string]interface{}, depths map[string]int, visited map[visit]bool) {
 	switch val := data.(type) {
 	case []interface{}:
 		for _, item := range val {
-			nestedMap := StructsToMap(attributeNames, item)
-			for k, v := range nestedMap {
-				result[k] = v
-			}
+			structsToMap(attributeNames, item, fieldNamer, depth, result, depths, visited)
 		}
 	case interface{}:
 		rValue := reflect.ValueOf(data)
-		if rValue.Kind() == reflect.Ptr {
+		for rValue.Kind() == reflect.Ptr || rValue.Kind() == reflect.Interface {
 			if rValue.IsNil() {
-				return result
+				return
+			}
+			if rValue.Kind() == reflect.Ptr {
+				key := visit{pointer: rValue.Pointer(), rType: rValue.Type()}
+				if visited[key] {
+					return
+				}
+				visited[key] = true
 			}
 			rValue = rValue.Elem()
 		}
+		if rValue.Kind() != reflect.Struct {
+			return
+		}
 		for i := 0; i < rValue.NumField(); i++ {
 			field := rValue.Field(i)
 			typeField := rValue.Type().Field(i)
-			key := typeField.Name
+			key := fieldNamer(typeField)
 
-			if field.Kind() == reflect.Struct {
-				nestedMap := StructsToMap(attributeNames, field.Interface())
-				for k, v := range nestedMap {
-					result[k] = v
-				}
+			if isNestedStruct(field) && field.CanInterface() {
+				structsToMap(attributeNames, field.Interface(), fieldNamer, depth+1, result, depths, visited)
 			}
 			if _, ok := attributeNames[key]; !ok {
 				continue
 			}
+			if d, ok := depths[key]; ok && d < depth {
+				continue
+			}
 			if field.CanInterface() {
 				result[key] = field.Interface()
+				depths[key] = depth
 			}
 		}
 	}
-	return result
+}
+
+func isNestedStruct(field reflect.Value) bool {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return false
+		}
+		return field.Elem().Kind() == reflect.Struct
+	}
+	return field.Kind() == reflect.Struct
 }
@@ -152,6 +152,29 @@ func TestStructsToMap(t *testing.T) {
 				"PartnerId":   "",
 			},
 		},
+		{
+			name: "Field hides nested field of the same name",
+			input: []interface{}{
+				struct {
+					Status  string
+					Payload struct{ Status string }
+				}{
+					Status:  "published",
+					Payload: struct{ Status string }{Status: "paid"},
+				},
+				struct {
+					Payload *struct{ Status string }
+				}{
+					Payload: &struct{ Status string }{Status: "completed"},
+				},
+			},
+			attributeNames: map[string]interface{}{
+				"Status": nil,
+			},
+			expected: map[string]interface{}{
+				"Status": "published",
+			},
+		},
 		{
 			name:     "Empty struct",
 			input:    struct{}{},
@@ -173,3 +196,26 @@ func TestStructsToMap(t *testing.T) {
 		})
 	}
 }
+
+type cyclicNode struct {
+	Name   string
+	Parent *cyclicNode
+	Child  *cyclicChild
+}
+
+type cyclicChild struct {
+	Status string
+	Node   *cyclicNode
+}
+
+func TestStructsToMap_Cycle(t *testing.T) {
+	node := &cyclicNode{Name: "root"}
+	node.Parent = node
+	node.Child = &cyclicChild{Status: "active", Node: node}
+
+	result := StructsToMap(map[string]interface{}{"Name": nil, "Status": nil}, node)
+	expected := map[string]interface{}{"Name": "root", "Status": "active"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("StructsToMap() = %v, want %v", result, expected)
+	}
+}
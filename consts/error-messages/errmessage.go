@@ -5,4 +5,5 @@ const (
 	ErrorMessageInvalidParameter   = "invalid parameter, %s is required"
 	ErrorMessageInvalidType        = "invalid type, %s is required"
 	ErrorMessageUnableToCastObject = "unable to cast object"
+	ErrorMessageUnsupported        = "%s is not supported"
 )
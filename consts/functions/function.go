@@ -0,0 +1,5 @@
+package functions
+
+const (
+	FunctionLen = "len"
+)
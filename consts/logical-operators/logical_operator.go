@@ -6,4 +6,5 @@ const (
 
 	LogicalOperatorAndSyntax = "&&"
 	LogicalOperatorOrSyntax  = "||"
+	LogicalOperatorNotSyntax = "!"
 )
@@ -9,4 +9,9 @@ const (
 	OperatorGreaterThanEqual   = ">="
 	OperatorContains           = "|="
 	OperatorContainsRegexMatch = "|~"
+	OperatorIn                 = "in"
+	OperatorNotIn              = "not in"
+	OperatorExists             = "exists"
+	OperatorIsNull             = "is null"
+	OperatorIsNotNull          = "is not null"
 )
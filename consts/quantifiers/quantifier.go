@@ -0,0 +1,7 @@
+package quantifiers
+
+const (
+	QuantifierAny  = "any"
+	QuantifierAll  = "all"
+	QuantifierNone = "none"
+)
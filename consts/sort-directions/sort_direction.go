@@ -0,0 +1,6 @@
+package sortdirections
+
+const (
+	SortDirectionAsc  = "asc"
+	SortDirectionDesc = "desc"
+)
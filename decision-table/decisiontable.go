@@ -0,0 +1,379 @@
+package decisiontable
+
+import (
+	"errors"
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/hit-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"reflect"
+	"strings"
+)
+
+// ErrNoMatch is returned when no row matches and the table has no default.
+var ErrNoMatch = errors.New("no row matched")
+
+// Row is a row of a decision table, given either as a query or as a
+// condition tree. Priority is only used by the PRIORITY hit policy, higher
+// wins.
+type Row[T any] struct {
+	Name      string
+	Query     string
+	Condition *structs.Condition
+	Priority  int
+	Output    T
+
+	index     int
+	validator validators.ConditionValidator
+}
+
+// Index returns the position of the row in its table.
+func (r *Row[T]) Index() int {
+	return r.index
+}
+
+func (r *Row[T]) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("row %d", r.index)
+	}
+	return fmt.Sprintf("row %d (%s)", r.index, r.Name)
+}
+
+// RowError is the error of one row.
+type RowError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// OverlapError is returned by the UNIQUE and ANY hit policies when rows that
+// must not overlap match the same data.
+type OverlapError struct {
+	Policy hitpolicies.HitPolicy
+	Rows   []int
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("%s hit policy violated, rows %v match", e.Policy, e.Rows)
+}
+
+// Decision is the outcome of a table for some data.
+type Decision[T any] struct {
+	Policy hitpolicies.HitPolicy
+	// Output is the output of the winning row, the aggregate of the
+	// COLLECT_SUM, COLLECT_MIN and COLLECT_MAX hit policies, or the default.
+	// It is the zero value for COLLECT.
+	Output T
+	// Outputs are the outputs of the matching rows in table order for
+	// COLLECT and its aggregations, the output of the winning row otherwise.
+	Outputs []T
+	// Matched are the rows that matched in table order, FIRST stops at the
+	// first one.
+	Matched []*Row[T]
+	// Winner is the row the output comes from, nil for the collecting hit
+	// policies and the default.
+	Winner    *Row[T]
+	IsDefault bool
+}
+
+// Explain tells in one line which rows matched and which one won.
+func (d Decision[T]) Explain() string {
+	var builder strings.Builder
+	builder.WriteString(d.Policy.ToString())
+	builder.WriteString(": ")
+	if len(d.Matched) == 0 {
+		builder.WriteString("no row matched")
+	} else {
+		rows := make([]string, len(d.Matched))
+		for i, row := range d.Matched {
+			rows[i] = row.String()
+		}
+		builder.WriteString(strings.Join(rows, ", "))
+		builder.WriteString(" matched")
+	}
+	switch {
+	case d.IsDefault:
+		fmt.Fprintf(&builder, ", default %v used", d.Output)
+	case d.Winner != nil:
+		fmt.Fprintf(&builder, ", %s won with %v", d.Winner, d.Output)
+	case len(d.Matched) == 0:
+	case d.Policy == hitpolicies.Collect:
+		fmt.Fprintf(&builder, ", collected %v", d.Outputs)
+	case d.Policy == hitpolicies.CollectSum, d.Policy == hitpolicies.CollectMin, d.Policy == hitpolicies.CollectMax:
+		fmt.Fprintf(&builder, ", aggregated %v", d.Output)
+	}
+	return builder.String()
+}
+
+// Table is a decision table: ordered rows of a condition and an output, and a
+// hit policy picking the outcome from the rows some data matches.
+type Table[T any] struct {
+	policy        hitpolicies.HitPolicy
+	rows          []*Row[T]
+	defaultOutput *T
+	removePrefix  bool
+	nameStrategy  namestrategies.NameStrategy
+	nameTag       string
+	nullPolicy    nullpolicies.NullPolicy
+	numericMode   numericmodes.NumericMode
+}
+
+// New returns an empty table with policy.
+func New[T any](policy hitpolicies.HitPolicy) (*Table[T], error) {
+	switch policy {
+	case hitpolicies.First, hitpolicies.Unique, hitpolicies.Any, hitpolicies.Priority, hitpolicies.Collect:
+	case hitpolicies.CollectSum, hitpolicies.CollectMin, hitpolicies.CollectMax:
+		var output T
+		if !isNumericType(reflect.TypeOf(&output).Elem()) {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "numeric output")
+		}
+	default:
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("hit policy %q", policy))
+	}
+	return &Table[T]{
+		policy:       policy,
+		nameStrategy: namestrategies.FieldName,
+		nullPolicy:   nullpolicies.False,
+		numericMode:  numericmodes.Float,
+	}, nil
+}
+
+// AddRow parses the row and adds it after the others. Its validator gets the
+// settings the table was given so far.
+func (t *Table[T]) AddRow(row *Row[T]) error {
+	if row == nil {
+		return fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+	}
+	condition := row.Condition
+	switch {
+	case row.Query != "" && condition != nil:
+		return errors.New("row has both a query and a condition")
+	case row.Query != "":
+		var gen structgen.StructGen
+		parsed, err := gen.GenerateCondition(row.Query)
+		if err != nil {
+			return err
+		}
+		condition = &parsed
+	case condition == nil:
+		return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "query or condition")
+	}
+	row.index = len(t.rows)
+	row.validator = validators.NewConditionValidator(condition)
+	t.configure(row.validator)
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+// configure applies the settings of the table to validator.
+func (t *Table[T]) configure(validator validators.ConditionValidator) {
+	validator.SetRemovePrefix(t.removePrefix)
+	if t.nameStrategy == namestrategies.Tag {
+		validator.SetNameTag(t.nameTag)
+	} else {
+		validator.SetNameStrategy(t.nameStrategy)
+	}
+	validator.SetNullPolicy(t.nullPolicy)
+	validator.SetNumericMode(t.numericMode)
+}
+
+// Add adds a row with query and output.
+func (t *Table[T]) Add(query string, output T) error {
+	return t.AddRow(&Row[T]{Query: query, Output: output})
+}
+
+// SetDefault sets the output used when no row matches.
+func (t *Table[T]) SetDefault(output T) *Table[T] {
+	t.defaultOutput = &output
+	return t
+}
+
+// Rows returns the rows of the table in order.
+func (t *Table[T]) Rows() []*Row[T] {
+	return t.rows
+}
+
+// SetRemovePrefix sets the prefix removal of the validators of every row,
+// including the ones added later, see Validator.SetRemovePrefix.
+func (t *Table[T]) SetRemovePrefix(value bool) *Table[T] {
+	t.removePrefix = value
+	for _, row := range t.rows {
+		row.validator.SetRemovePrefix(value)
+	}
+	return t
+}
+
+// SetNameStrategy sets the name strategy of the validators of every row.
+func (t *Table[T]) SetNameStrategy(strategy namestrategies.NameStrategy) *Table[T] {
+	t.nameStrategy, t.nameTag = strategy, ""
+	for _, row := range t.rows {
+		row.validator.SetNameStrategy(strategy)
+	}
+	return t
+}
+
+// SetNameTag sets the name tag of the validators of every row.
+func (t *Table[T]) SetNameTag(tagKey string) *Table[T] {
+	t.nameStrategy, t.nameTag = namestrategies.Tag, tagKey
+	for _, row := range t.rows {
+		row.validator.SetNameTag(tagKey)
+	}
+	return t
+}
+
+// SetNullPolicy sets the null policy of the validators of every row, see
+// Validator.SetNullPolicy.
+func (t *Table[T]) SetNullPolicy(policy nullpolicies.NullPolicy) *Table[T] {
+	t.nullPolicy = policy
+	for _, row := range t.rows {
+		row.validator.SetNullPolicy(policy)
+	}
+	return t
+}
+
+// SetNumericMode sets the numeric mode of the validators of every row, see
+// Validator.SetNumericMode.
+func (t *Table[T]) SetNumericMode(mode numericmodes.NumericMode) *Table[T] {
+	t.numericMode = mode
+	for _, row := range t.rows {
+		row.validator.SetNumericMode(mode)
+	}
+	return t
+}
+
+// Decide validates data against the rows and applies the hit policy. When no
+// row matches the default is used, without one ErrNoMatch is returned. A
+// row failing to validate data stops the decision with a *RowError.
+func (t *Table[T]) Decide(data interface{}) (decision Decision[T], err error) {
+	decision.Policy = t.policy
+	for _, row := range t.rows {
+		isValid, err := row.validator.Validate(data)
+		if err != nil {
+			return Decision[T]{Policy: t.policy}, &RowError{Index: row.index, Name: row.Name, Err: err}
+		}
+		if !isValid {
+			continue
+		}
+		decision.Matched = append(decision.Matched, row)
+		if t.policy == hitpolicies.First {
+			break
+		}
+	}
+	if len(decision.Matched) == 0 {
+		if t.defaultOutput == nil {
+			return decision, ErrNoMatch
+		}
+		decision.Output = *t.defaultOutput
+		decision.Outputs = []T{decision.Output}
+		decision.IsDefault = true
+		return decision, nil
+	}
+
+	switch t.policy {
+	case hitpolicies.First:
+		decision.Winner = decision.Matched[0]
+	case hitpolicies.Unique, hitpolicies.Any:
+		if t.policy == hitpolicies.Unique && len(decision.Matched) > 1 || !sameOutputs(decision.Matched) {
+			return decision, &OverlapError{Policy: t.policy, Rows: rowIndexes(decision.Matched)}
+		}
+		decision.Winner = decision.Matched[0]
+	case hitpolicies.Priority:
+		decision.Winner = decision.Matched[0]
+		for _, row := range decision.Matched[1:] {
+			if row.Priority > decision.Winner.Priority {
+				decision.Winner = row
+			}
+		}
+	default:
+		for _, row := range decision.Matched {
+			decision.Outputs = append(decision.Outputs, row.Output)
+		}
+		if t.policy != hitpolicies.Collect {
+			decision.Output = aggregate(t.policy, decision.Outputs)
+		}
+		return decision, nil
+	}
+	decision.Output = decision.Winner.Output
+	decision.Outputs = []T{decision.Output}
+	return decision, nil
+}
+
+func sameOutputs[T any](rows []*Row[T]) bool {
+	for _, row := range rows[1:] {
+		if !reflect.DeepEqual(row.Output, rows[0].Output) {
+			return false
+		}
+	}
+	return true
+}
+
+func rowIndexes[T any](rows []*Row[T]) []int {
+	indexes := make([]int, len(rows))
+	for i, row := range rows {
+		indexes[i] = row.index
+	}
+	return indexes
+}
+
+func isNumericType(rType reflect.Type) bool {
+	switch rType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// aggregate sums the outputs or picks the smallest or largest one, outputs
+// are of a numeric type as New checks.
+func aggregate[T any](policy hitpolicies.HitPolicy, outputs []T) T {
+	result := reflect.New(reflect.TypeOf(&outputs[0]).Elem()).Elem()
+	result.Set(reflect.ValueOf(&outputs[0]).Elem())
+	for _, output := range outputs[1:] {
+		value := reflect.ValueOf(&output).Elem()
+		switch policy {
+		case hitpolicies.CollectSum:
+			switch {
+			case result.CanInt():
+				result.SetInt(result.Int() + value.Int())
+			case result.CanUint():
+				result.SetUint(result.Uint() + value.Uint())
+			default:
+				result.SetFloat(result.Float() + value.Float())
+			}
+		case hitpolicies.CollectMin:
+			if less(value, result) {
+				result.Set(value)
+			}
+		case hitpolicies.CollectMax:
+			if less(result, value) {
+				result.Set(value)
+			}
+		}
+	}
+	return result.Interface().(T)
+}
+
+func less(first, second reflect.Value) bool {
+	switch {
+	case first.CanInt():
+		return first.Int() < second.Int()
+	case first.CanUint():
+		return first.Uint() < second.Uint()
+	}
+	return first.Float() < second.Float()
+}
@@ -0,0 +1,252 @@
+package decisiontable
+
+import (
+	"errors"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/hit-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"reflect"
+	"testing"
+)
+
+type transfer struct {
+	Channel string
+	Bank    string
+	Amount  float64
+}
+
+func newFeeTable(t *testing.T, policy hitpolicies.HitPolicy) *Table[int] {
+	table, err := New[int](policy)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	rows := []*Row[int]{
+		{Name: "same bank", Query: `Bank = bca && Channel = mobile`, Output: 0, Priority: 3},
+		{Name: "mobile", Query: `Channel = mobile`, Output: 2500, Priority: 1},
+		{Name: "large", Query: `Amount >= 100000000`, Output: 5000, Priority: 2},
+		{Name: "teller", Query: `Channel = teller`, Output: 6500},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	return table
+}
+
+func TestTable_Decide(t *testing.T) {
+	sameBank := transfer{Channel: "mobile", Bank: "bca", Amount: 200000000}
+	tests := []struct {
+		name        string
+		policy      hitpolicies.HitPolicy
+		data        transfer
+		wantOutput  int
+		wantOutputs []int
+		wantMatched []int
+		wantWinner  int
+		wantExplain string
+	}{
+		{
+			name:        "first",
+			policy:      hitpolicies.First,
+			data:        sameBank,
+			wantOutput:  0,
+			wantOutputs: []int{0},
+			wantMatched: []int{0},
+			wantWinner:  0,
+			wantExplain: "FIRST: row 0 (same bank) matched, row 0 (same bank) won with 0",
+		},
+		{
+			name:        "priority",
+			policy:      hitpolicies.Priority,
+			data:        transfer{Channel: "mobile", Bank: "bni", Amount: 200000000},
+			wantOutput:  5000,
+			wantOutputs: []int{5000},
+			wantMatched: []int{1, 2},
+			wantWinner:  2,
+			wantExplain: "PRIORITY: row 1 (mobile), row 2 (large) matched, row 2 (large) won with 5000",
+		},
+		{
+			name:        "unique",
+			policy:      hitpolicies.Unique,
+			data:        transfer{Channel: "teller", Amount: 10},
+			wantOutput:  6500,
+			wantOutputs: []int{6500},
+			wantMatched: []int{3},
+			wantWinner:  3,
+		},
+		{
+			name:        "collect",
+			policy:      hitpolicies.Collect,
+			data:        sameBank,
+			wantOutputs: []int{0, 2500, 5000},
+			wantMatched: []int{0, 1, 2},
+			wantWinner:  -1,
+			wantExplain: "COLLECT: row 0 (same bank), row 1 (mobile), row 2 (large) matched, collected [0 2500 5000]",
+		},
+		{
+			name:        "collect sum",
+			policy:      hitpolicies.CollectSum,
+			data:        sameBank,
+			wantOutput:  7500,
+			wantOutputs: []int{0, 2500, 5000},
+			wantMatched: []int{0, 1, 2},
+			wantWinner:  -1,
+			wantExplain: "COLLECT_SUM: row 0 (same bank), row 1 (mobile), row 2 (large) matched, aggregated 7500",
+		},
+		{
+			name:        "collect min",
+			policy:      hitpolicies.CollectMin,
+			data:        transfer{Channel: "mobile", Amount: 200000000},
+			wantOutput:  2500,
+			wantOutputs: []int{2500, 5000},
+			wantMatched: []int{1, 2},
+			wantWinner:  -1,
+		},
+		{
+			name:        "collect max",
+			policy:      hitpolicies.CollectMax,
+			data:        sameBank,
+			wantOutput:  5000,
+			wantOutputs: []int{0, 2500, 5000},
+			wantMatched: []int{0, 1, 2},
+			wantWinner:  -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := newFeeTable(t, tt.policy).Decide(tt.data)
+			if err != nil {
+				t.Fatalf("Decide() error = %v", err)
+			}
+			var matched []int
+			for _, row := range decision.Matched {
+				matched = append(matched, row.Index())
+			}
+			winner := -1
+			if decision.Winner != nil {
+				winner = decision.Winner.Index()
+			}
+			if decision.Output != tt.wantOutput || !reflect.DeepEqual(decision.Outputs, tt.wantOutputs) || !reflect.DeepEqual(matched, tt.wantMatched) || winner != tt.wantWinner || decision.IsDefault {
+				t.Errorf("Decide() = %v %v, matched %v, winner %d, want %v %v, matched %v, winner %d",
+					decision.Output, decision.Outputs, matched, winner, tt.wantOutput, tt.wantOutputs, tt.wantMatched, tt.wantWinner)
+			}
+			if tt.wantExplain != "" && decision.Explain() != tt.wantExplain {
+				t.Errorf("Explain() = %q, want %q", decision.Explain(), tt.wantExplain)
+			}
+		})
+	}
+}
+
+func TestTable_DecideErrors(t *testing.T) {
+	table := newFeeTable(t, hitpolicies.Unique)
+	_, err := table.Decide(transfer{Channel: "mobile", Amount: 200000000})
+	var overlap *OverlapError
+	if !errors.As(err, &overlap) || !reflect.DeepEqual(overlap.Rows, []int{1, 2}) {
+		t.Errorf("Decide() error = %v, want *OverlapError on rows [1 2]", err)
+	}
+
+	table = newFeeTable(t, hitpolicies.Any)
+	if err := table.Add(`Bank = bni`, 2500); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	decision, err := table.Decide(transfer{Channel: "mobile", Bank: "bni"})
+	if err != nil || decision.Output != 2500 || decision.Winner.Index() != 1 {
+		t.Errorf("Decide() = %v, %v, want 2500 from row 1", decision.Output, err)
+	}
+	decision, err = table.Decide(transfer{Channel: "mobile", Bank: "bni", Amount: 200000000})
+	if !errors.As(err, &overlap) || decision.Explain() != "ANY: row 1 (mobile), row 2 (large), row 4 matched" {
+		t.Errorf("Decide() = %q, %v, want *OverlapError", decision.Explain(), err)
+	}
+
+	decision, err = table.Decide(transfer{Channel: "atm"})
+	if !errors.Is(err, ErrNoMatch) || decision.Explain() != "ANY: no row matched" {
+		t.Errorf("Decide() = %q, %v, want ErrNoMatch", decision.Explain(), err)
+	}
+	decision, err = table.SetDefault(7500).Decide(transfer{Channel: "atm"})
+	if err != nil || !decision.IsDefault || decision.Output != 7500 || decision.Explain() != "ANY: no row matched, default 7500 used" {
+		t.Errorf("Decide() = %+v, %v, want default 7500", decision, err)
+	}
+
+	var rowErr *RowError
+	if decision, err := table.Decide(nil); !errors.As(err, &rowErr) || rowErr.Index != 0 || decision.Explain() != "ANY: no row matched" {
+		t.Errorf("Decide(nil) = %q, %v, want *RowError of row 0", decision.Explain(), err)
+	}
+	if decision, err := newFeeTable(t, hitpolicies.CollectSum).Decide(transfer{Channel: "atm"}); !errors.Is(err, ErrNoMatch) || decision.Explain() != "COLLECT_SUM: no row matched" {
+		t.Errorf("Decide() = %q, %v, want ErrNoMatch", decision.Explain(), err)
+	}
+	if err := table.Add(`Channel = (mobile`, 0); err == nil {
+		t.Errorf("Add() error = nil, want parse error")
+	}
+	if _, err := New[string](hitpolicies.CollectSum); err == nil {
+		t.Errorf("New[string](COLLECT_SUM) error = nil, want error")
+	}
+	if _, err := New[int]("RULE ORDER"); err == nil {
+		t.Errorf("New(RULE ORDER) error = nil, want error")
+	}
+}
+
+func TestTable_SetNameTag(t *testing.T) {
+	table, _ := New[string](hitpolicies.First)
+	_ = table.Add(`tier = gold`, "free")
+	_ = table.Add(`amount > 100`, "flat")
+	table.SetNameTag("json")
+	decision, err := table.Decide(struct {
+		Tier   string  `json:"tier"`
+		Amount float64 `json:"amount"`
+	}{Tier: "silver", Amount: 150})
+	if err != nil || decision.Output != "flat" {
+		t.Errorf("Decide() = %v, %v, want flat", decision.Output, err)
+	}
+}
+
+func TestTable_SetNullPolicy(t *testing.T) {
+	table, _ := New[string](hitpolicies.First)
+	_ = table.Add(`Discount = 0`, "full price")
+	_ = table.Add(`Discount > 0`, "discounted")
+	data := struct{ Discount *float64 }{}
+	if _, err := table.Decide(data); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Decide() error = %v, want ErrNoMatch", err)
+	}
+	table.SetNullPolicy(nullpolicies.ZeroValue)
+	decision, err := table.Decide(data)
+	if err != nil || decision.Output != "full price" {
+		t.Errorf("Decide() = %v, %v, want full price", decision.Output, err)
+	}
+}
+
+func TestTable_SettingsBeforeAdd(t *testing.T) {
+	table, _ := New[string](hitpolicies.First)
+	table.SetNullPolicy(nullpolicies.Error).SetNumericMode(numericmodes.Decimal).SetNameTag("json")
+	_ = table.Add(`sender = x`, "sender")
+	_ = table.Add(`rate = 0.3`, "standard")
+
+	type event struct {
+		Sender *string `json:"sender"`
+		Rate   string  `json:"rate"`
+	}
+	var unknown *validators.UnknownAttributeError
+	if _, err := table.Decide(event{Rate: "0.30"}); !errors.As(err, &unknown) || unknown.Path != "sender" {
+		t.Errorf("Decide() error = %v, want unknown attribute sender", err)
+	}
+	sender := "y"
+	decision, err := table.Decide(event{Sender: &sender, Rate: "0.30"})
+	if err != nil || decision.Output != "standard" {
+		t.Errorf("Decide() = %v, %v, want standard", decision.Output, err)
+	}
+}
+
+func TestTable_SetNumericMode(t *testing.T) {
+	table, _ := New[string](hitpolicies.First)
+	_ = table.Add(`Rate = 0.3`, "standard")
+	data := struct{ Rate string }{Rate: "0.30"}
+	if _, err := table.Decide(data); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Decide() error = %v, want ErrNoMatch", err)
+	}
+	table.SetNumericMode(numericmodes.Decimal)
+	decision, err := table.Decide(data)
+	if err != nil || decision.Output != "standard" {
+		t.Errorf("Decide() = %v, %v, want standard", decision.Output, err)
+	}
+}
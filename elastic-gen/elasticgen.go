@@ -0,0 +1,289 @@
+package elasticgen
+
+import (
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/field-types"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strings"
+)
+
+var rangeOperators = map[string]string{
+	operators.OperatorLessThan:         "lt",
+	operators.OperatorLessThanEqual:    "lte",
+	operators.OperatorGreaterThan:      "gt",
+	operators.OperatorGreaterThanEqual: "gte",
+}
+
+// FieldMapper maps the attribute name of a comparison to the field of the
+// index and tells whether it is a keyword or a text field.
+type FieldMapper func(name string) (field string, fieldType fieldtypes.FieldType, err error)
+
+// DefaultFieldMapper uses the attribute name as a keyword field.
+func DefaultFieldMapper(name string) (string, fieldtypes.FieldType, error) {
+	return name, fieldtypes.Keyword, nil
+}
+
+type ElasticGen struct {
+	fieldMapper FieldMapper
+}
+
+func NewElasticGen() *ElasticGen {
+	return &ElasticGen{
+		fieldMapper: DefaultFieldMapper,
+	}
+}
+
+// SetFieldMapper sets the mapping of attribute names to index fields.
+func (e *ElasticGen) SetFieldMapper(mapper FieldMapper) *ElasticGen {
+	e.fieldMapper = mapper
+	return e
+}
+
+// clause is a translated term, isFullText tells it contributes to the score
+// and belongs in `must` rather than `filter` when it is and-ed.
+type clause struct {
+	query      map[string]interface{}
+	isFullText bool
+}
+
+// Query translates condition into an Elasticsearch / OpenSearch query. And-ed
+// terms go to the `filter` of a bool query, or its `must` when they are full
+// text queries, or-ed terms to `should` and negated ones to `must_not`. A
+// group mixing && and || gets a bool query per run of the same operator. An
+// empty condition gives a `match_all` query. Quantifiers become `nested`
+// queries, the collection has to be mapped as nested.
+func (e *ElasticGen) Query(condition structs.Condition) (map[string]interface{}, error) {
+	if condition.IsEmpty() {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	}
+	var result clause
+	var err error
+	if condition.Quantifier == "" && len(condition.Conditions) > 0 && !condition.Negate {
+		result, err = e.sequence(condition.Conditions)
+	} else {
+		result, err = e.term(&condition)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.query, nil
+}
+
+// sequence translates the terms of a group, `a || b && c` as a bool query
+// whose filter holds the should of `a || b`, and c.
+func (e *ElasticGen) sequence(conditions []*structs.Condition) (clause, error) {
+	operator, run, err := structs.Fold(conditions, e.term, combine)
+	if err != nil {
+		return clause{}, err
+	}
+	if len(run) == 1 {
+		return run[0], nil
+	}
+	return combine(operator, run), nil
+}
+
+// combine joins clauses with the logical operator into a bool query.
+func combine(operator string, clauses []clause) clause {
+	boolQuery := make(map[string]interface{})
+	isFullText := false
+	var must, filter, should []interface{}
+	for _, c := range clauses {
+		isFullText = isFullText || c.isFullText
+		switch {
+		case operator == logicaloperators.LogicalOperatorOr:
+			should = append(should, c.query)
+		case c.isFullText:
+			must = append(must, c.query)
+		default:
+			filter = append(filter, c.query)
+		}
+	}
+	if len(must) > 0 {
+		boolQuery["must"] = must
+	}
+	if len(filter) > 0 {
+		boolQuery["filter"] = filter
+	}
+	if len(should) > 0 {
+		boolQuery["should"] = should
+		boolQuery["minimum_should_match"] = 1
+	}
+	return clause{
+		query:      map[string]interface{}{"bool": boolQuery},
+		isFullText: isFullText,
+	}
+}
+
+// negate wraps query in the must_not of a bool query.
+func negate(query map[string]interface{}) clause {
+	return clause{
+		query: map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": []interface{}{query},
+			},
+		},
+	}
+}
+
+func (e *ElasticGen) term(condition *structs.Condition) (term clause, err error) {
+	switch {
+	case condition.Quantifier != "":
+		term, err = e.quantifier(condition)
+	case len(condition.Conditions) > 0:
+		term, err = e.sequence(condition.Conditions)
+	case condition.Attribute != nil:
+		term, err = e.comparison(condition.Attribute)
+	default:
+		return clause{}, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "attribute")
+	}
+	if err != nil || !condition.Negate {
+		return term, err
+	}
+	return negate(term.query), nil
+}
+
+// quantifier translates `any` to a nested query, `none` to its negation and
+// `all` to the negation of a nested query on the negated conditions.
+func (e *ElasticGen) quantifier(condition *structs.Condition) (clause, error) {
+	path, _, err := e.fieldMapper(condition.Collection)
+	if err != nil {
+		return clause{}, err
+	}
+	inner, err := e.sequence(condition.Conditions)
+	if err != nil {
+		return clause{}, err
+	}
+	nested := func(query map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"nested": map[string]interface{}{
+				"path":  path,
+				"query": query,
+			},
+		}
+	}
+	switch condition.Quantifier {
+	case quantifiers.QuantifierAny:
+		return clause{query: nested(inner.query), isFullText: inner.isFullText}, nil
+	case quantifiers.QuantifierNone:
+		return negate(nested(inner.query)), nil
+	case quantifiers.QuantifierAll:
+		return negate(nested(negate(inner.query).query)), nil
+	}
+	return clause{}, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("quantifier %s", condition.Quantifier))
+}
+
+func (e *ElasticGen) comparison(attribute *structs.Attribute) (clause, error) {
+	if attribute.Function != "" {
+		return clause{}, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("function %s", attribute.Function))
+	}
+	if attribute.Type == valuetypes.Field {
+		return clause{}, fmt.Errorf(errormessages.ErrorMessageUnsupported, "field reference")
+	}
+	field, fieldType, err := e.fieldMapper(attribute.Name)
+	if err != nil {
+		return clause{}, err
+	}
+	isText := fieldType == fieldtypes.Text
+
+	switch attribute.Operator {
+	case operators.OperatorEqual, operators.OperatorNotEqual:
+		value := structgen.TypedValue(attribute.Value, attribute.Type)
+		term := clause{query: leaf("term", field, value)}
+		if isText {
+			term = clause{query: leaf("match_phrase", field, value), isFullText: true}
+		}
+		if attribute.Operator == operators.OperatorNotEqual {
+			return negate(term.query), nil
+		}
+		return term, nil
+	case operators.OperatorIn, operators.OperatorNotIn:
+		values := make([]interface{}, len(attribute.Values))
+		for i, value := range attribute.Values {
+			values[i] = structgen.TypedValue(value, attribute.Type)
+		}
+		term := clause{query: leaf("terms", field, values)}
+		if isText {
+			phrases := make([]clause, len(values))
+			for i, value := range values {
+				phrases[i] = clause{query: leaf("match_phrase", field, value), isFullText: true}
+			}
+			term = combine(logicaloperators.LogicalOperatorOr, phrases)
+		}
+		if attribute.Operator == operators.OperatorNotIn {
+			return negate(term.query), nil
+		}
+		return term, nil
+	case operators.OperatorContains:
+		if isText {
+			return clause{query: leaf("match_phrase", field, attribute.Value), isFullText: true}, nil
+		}
+		return clause{query: leaf("wildcard", field, map[string]interface{}{
+			"value": "*" + escapeWildcard(attribute.Value) + "*",
+		})}, nil
+	case operators.OperatorContainsRegexMatch:
+		return clause{query: leaf("regexp", field, map[string]interface{}{
+			"value": luceneRegexp(attribute.Value),
+		})}, nil
+	case operators.OperatorExists, operators.OperatorIsNotNull, operators.OperatorIsNull:
+		// Elasticsearch doesn't index nulls, a field holding one doesn't
+		// exist either
+		exists := map[string]interface{}{"exists": map[string]interface{}{"field": field}}
+		if attribute.Operator == operators.OperatorIsNull {
+			return negate(exists), nil
+		}
+		return clause{query: exists}, nil
+	}
+
+	operator, ok := rangeOperators[attribute.Operator]
+	if !ok {
+		return clause{}, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", attribute.Operator))
+	}
+	return clause{query: leaf("range", field, map[string]interface{}{
+		operator: structgen.TypedValue(attribute.Value, attribute.Type),
+	})}, nil
+}
+
+func leaf(queryType, field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		queryType: map[string]interface{}{
+			field: value,
+		},
+	}
+}
+
+// escapeWildcard escapes the characters of value a wildcard query gives a
+// meaning to.
+func escapeWildcard(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		if char == '*' || char == '?' || char == '\\' {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+// luceneRegexp anchors pattern the way Lucene does implicitly: a pattern
+// without `^` or `$` gets `.*` on that side so it still matches anywhere in
+// the value. The pattern is grouped first, so `a|b` becomes `.*(a|b).*`
+// rather than `.*a|b.*`. Other differences to the Go syntax, e.g. `\s`, are
+// left as is.
+func luceneRegexp(pattern string) string {
+	prefix, suffix := ".*", ".*"
+	if strings.HasPrefix(pattern, "^") {
+		pattern = pattern[1:]
+		prefix = ""
+	}
+	if strings.HasSuffix(pattern, "$") && !strings.HasSuffix(pattern, `\$`) {
+		pattern = pattern[:len(pattern)-1]
+		suffix = ""
+	}
+	return prefix + "(" + pattern + ")" + suffix
+}
@@ -0,0 +1,93 @@
+package elasticgen
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/field-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// textFields maps the attributes named *Name to text fields.
+func textFields(name string) (string, fieldtypes.FieldType, error) {
+	if strings.HasSuffix(name, "Name") {
+		return name, fieldtypes.Text, nil
+	}
+	return name, fieldtypes.Keyword, nil
+}
+
+func TestElasticGen_Query(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		mapper FieldMapper
+	}{
+		{name: "groups", query: `(ID=1 && (MemberID=12||MemberID=2))  &&   (Division=engineering || Division=finance)`},
+		{name: "or_of_groups", query: `(id=1 &&  member_id=2  &&   (division=engineering || division=finance))||(member_id=3&&brand=abc)`},
+		{name: "negation", query: `ID=1 && !(Division=engineering || Division=finance) && !ID=2 && MemberID!=1232323`},
+		{name: "lists", query: `ID not in (1, 2, 3) || Division not in (engineering, finance) || Type in (ABC, DEF)`},
+		{name: "ranges", query: `JoinDate>"2015-01-01T00:00:00+07:00" && JoinDate<=2016-01-01T00:00:00+07:00 && Score>80 && Money>=1500000.5`},
+		{name: "contains_and_regex", query: `Division|=eng* && Division |~     "katak[\s][a-z]+[\s][0-9]+"`},
+		{name: "regex_alternation", query: `Division |~ "eng|fin" && Name |~ "^(reza|budi)$"`},
+		{name: "grouped_or_and_and", query: `(ID=1234 || secondStruct=Test || Segment=new-member) && (MemberID=345 && Name=Test) && Type=ABC`},
+		{name: "quantifiers", query: `any(Items, Items.Amount > 1000) && none(Items, Items.Code = x) && all(Items, Items.Paid = true)`},
+		{name: "text_fields", query: `FullName=Test && MemberID=345 && (NickName |= rez || NickName in (budi, ahmad))`, mapper: textFields},
+		{name: "nulls", query: `exists(SenderType) && Partner is null && !(Account is not null)`},
+		{name: "empty", query: ``},
+	}
+	var gen structgen.StructGen
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := gen.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			elastic := NewElasticGen()
+			if tt.mapper != nil {
+				elastic.SetFieldMapper(tt.mapper)
+			}
+			query, err := elastic.Query(condition)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			got, _ := json.MarshalIndent(query, "", "  ")
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", tt.name+".json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Query() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestElasticGen_QueryError(t *testing.T) {
+	queries := []string{
+		`len(Items) > 1`,
+		`Debit = $Credit`,
+	}
+	var gen structgen.StructGen
+	for _, query := range queries {
+		condition, err := gen.GenerateCondition(query)
+		if err != nil {
+			t.Fatalf("GenerateCondition(%q) error = %v", query, err)
+		}
+		if _, err := NewElasticGen().Query(condition); err == nil {
+			t.Errorf("Query(%q) error = nil, want unsupported", query)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package fieldtypes
+
+// FieldType is how a search index stores a field, which decides the query a
+// comparison on it is translated to.
+type FieldType string
+
+const (
+	// Keyword fields hold the exact value and are matched with term level
+	// queries.
+	Keyword FieldType = "keyword"
+	// Text fields are analyzed and matched with full text queries.
+	Text FieldType = "text"
+)
+
+func FromString(value string) FieldType {
+	return FieldType(value)
+}
+
+func (f FieldType) ToString() string {
+	return string(f)
+}
@@ -0,0 +1,33 @@
+package hitpolicies
+
+// HitPolicy tells how a decision table picks its outcome from the rows that
+// match, after the hit policies of DMN.
+type HitPolicy string
+
+const (
+	// First picks the first matching row in table order.
+	First HitPolicy = "FIRST"
+	// Unique allows at most one matching row.
+	Unique HitPolicy = "UNIQUE"
+	// Any allows several matching rows as long as their outputs are equal.
+	Any HitPolicy = "ANY"
+	// Priority picks the matching row with the highest priority, the first
+	// one of them on a tie.
+	Priority HitPolicy = "PRIORITY"
+	// Collect gives the outputs of every matching row in table order.
+	Collect HitPolicy = "COLLECT"
+	// CollectSum gives the sum of the numeric outputs of the matching rows.
+	CollectSum HitPolicy = "COLLECT_SUM"
+	// CollectMin gives the smallest numeric output of the matching rows.
+	CollectMin HitPolicy = "COLLECT_MIN"
+	// CollectMax gives the largest numeric output of the matching rows.
+	CollectMax HitPolicy = "COLLECT_MAX"
+)
+
+func FromString(value string) HitPolicy {
+	return HitPolicy(value)
+}
+
+func (h HitPolicy) ToString() string {
+	return string(h)
+}
@@ -0,0 +1,20 @@
+package namestrategies
+
+// NameStrategy decides which name of a struct field the attribute names of a
+// condition are matched against.
+type NameStrategy string
+
+const (
+	FieldName NameStrategy = "field_name"
+	JSONTag   NameStrategy = "json_tag"
+	Tag       NameStrategy = "tag"
+	SnakeCase NameStrategy = "snake_case"
+)
+
+func FromString(value string) NameStrategy {
+	return NameStrategy(value)
+}
+
+func (n NameStrategy) ToString() string {
+	return string(n)
+}
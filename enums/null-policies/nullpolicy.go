@@ -0,0 +1,28 @@
+package nullpolicies
+
+// NullPolicy decides what a comparison gives when its field is nil, or its
+// attribute path can't be resolved in the data.
+type NullPolicy string
+
+const (
+	// False makes the comparison false, `!` turns it true. It is the
+	// default.
+	False NullPolicy = "FALSE"
+	// ZeroValue compares the zero value of the field type instead, or of the
+	// type of the condition value when the field type isn't known.
+	ZeroValue NullPolicy = "ZERO_VALUE"
+	// Unknown makes the comparison unknown, like a comparison with NULL in
+	// SQL: `!` keeps it unknown, `&&` with false is false, `||` with true is
+	// true, and a condition that ends up unknown doesn't match.
+	Unknown NullPolicy = "UNKNOWN"
+	// Error fails the validation with an *UnknownAttributeError.
+	Error NullPolicy = "ERROR"
+)
+
+func FromString(value string) NullPolicy {
+	return NullPolicy(value)
+}
+
+func (n NullPolicy) ToString() string {
+	return string(n)
+}
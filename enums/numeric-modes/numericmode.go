@@ -0,0 +1,22 @@
+package numericmodes
+
+// NumericMode decides how numbers are compared.
+type NumericMode string
+
+const (
+	// Float compares integers as integers and floats as float64, parsing a
+	// condition value as the type of its field. It is the default.
+	Float NumericMode = "FLOAT"
+	// Decimal compares every number exactly as a math/big rational: integers
+	// of any size, floats as the shortest decimal that prints them, strings
+	// holding a decimal, and condition values as the decimal they spell.
+	Decimal NumericMode = "DECIMAL"
+)
+
+func FromString(value string) NumericMode {
+	return NumericMode(value)
+}
+
+func (n NumericMode) ToString() string {
+	return string(n)
+}
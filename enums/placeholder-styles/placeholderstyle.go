@@ -0,0 +1,20 @@
+package placeholderstyles
+
+// PlaceholderStyle is how the arguments of a generated WHERE clause are
+// referenced in the SQL text.
+type PlaceholderStyle string
+
+const (
+	// Question numbers nothing, every argument is a `?`.
+	Question PlaceholderStyle = "question"
+	// Dollar numbers the arguments `$1`, `$2`, ...
+	Dollar PlaceholderStyle = "dollar"
+)
+
+func FromString(value string) PlaceholderStyle {
+	return PlaceholderStyle(value)
+}
+
+func (p PlaceholderStyle) ToString() string {
+	return string(p)
+}
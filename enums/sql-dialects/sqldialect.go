@@ -0,0 +1,18 @@
+package sqldialects
+
+// SQLDialect is the database a generated WHERE clause is written for.
+type SQLDialect string
+
+const (
+	Postgres SQLDialect = "postgres"
+	MySQL    SQLDialect = "mysql"
+	SQLite   SQLDialect = "sqlite"
+)
+
+func FromString(value string) SQLDialect {
+	return SQLDialect(value)
+}
+
+func (s SQLDialect) ToString() string {
+	return string(s)
+}
@@ -6,6 +6,8 @@ const (
 	Numeric      ValueType = "numeric"
 	Alphanumeric ValueType = "alphanumeric"
 	Date         ValueType = "date"
+	// Field marks a value that names another attribute to compare against.
+	Field ValueType = "field"
 )
 
 func FromString(value string) ValueType {
@@ -0,0 +1,40 @@
+package deepvalidator
+
+import (
+	structgen "github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+)
+
+/*
+Errors
+-----------------------------------------------------------------------
+every error of the validators is one of the types below, match them
+with errors.As, or with errors.Is against the sentinels:
+  - *ParseError, ErrParse: the query can't be parsed
+  - *InvalidConditionError, ErrInvalidCondition: the condition can't be
+    evaluated, e.g. an unknown quantifier or no condition
+  - *InvalidInputError, ErrInvalidInput: the data is nil, empty or of a
+    type the operation doesn't take
+  - *TypeMismatchError, ErrTypeMismatch: a condition value can't be
+    converted to the type of its field, e.g. `Total = abc` on an int,
+    or a converter fails on the field value
+  - *UnknownAttributeError, ErrUnknownAttribute: an attribute path
+    can't be resolved in the data or its value is nil, only returned
+    under nullpolicies.Error, see SetNullPolicy. The other policies
+    treat such a field as null instead of failing
+*/
+var (
+	ErrParse            = structgen.ErrParse
+	ErrInvalidCondition = validators.ErrInvalidCondition
+	ErrInvalidInput     = validators.ErrInvalidInput
+	ErrTypeMismatch     = validators.ErrTypeMismatch
+	ErrUnknownAttribute = validators.ErrUnknownAttribute
+)
+
+type (
+	ParseError            = structgen.ParseError
+	InvalidConditionError = validators.InvalidConditionError
+	InvalidInputError     = validators.InvalidInputError
+	TypeMismatchError     = validators.TypeMismatchError
+	UnknownAttributeError = validators.UnknownAttributeError
+)
@@ -65,7 +65,7 @@ type TransactionUpdatedEvent struct {
 }
 
 func main() {
-	query := `(PartnerId=bca && Debit=50000 && TotalAmount=50000)`
+	query := `(PartnerId=bca && Debit=50000 && PartnerStatementUpdatedEvent.Payload.Debit=$TransactionUpdatedEvent.Payload.TotalAmount)`
 	data := []interface{}{
 		PartnerStatementUpdatedEvent{
 			EventSource:          "",
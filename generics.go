@@ -0,0 +1,134 @@
+package deepvalidator
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"reflect"
+)
+
+/*
+Validate
+-----------------------------------------------------------------------
+is the typed ValidateStruct.
+*/
+func Validate[T any](v Validator, item T) (isValid bool, err error) {
+	return v.ValidateStruct(item)
+}
+
+/*
+Filter
+-----------------------------------------------------------------------
+is the typed FilterSlice, it returns the items matching the condition
+in their order.
+*/
+func Filter[T any](v Validator, items []T) (result []T, err error) {
+	match, err := newMatcher[T](v)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]T, 0, len(items))
+	for _, item := range items {
+		isValid, err := match(item)
+		if err != nil {
+			return nil, err
+		}
+		if isValid {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+/*
+Partition
+-----------------------------------------------------------------------
+splits the items into the ones matching the condition and the others,
+both in their order.
+*/
+func Partition[T any](v Validator, items []T) (matched, unmatched []T, err error) {
+	match, err := newMatcher[T](v)
+	if err != nil {
+		return nil, nil, err
+	}
+	matched, unmatched = make([]T, 0), make([]T, 0)
+	for _, item := range items {
+		isValid, err := match(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isValid {
+			matched = append(matched, item)
+		} else {
+			unmatched = append(unmatched, item)
+		}
+	}
+	return matched, unmatched, nil
+}
+
+/*
+Find
+-----------------------------------------------------------------------
+returns the first item matching the condition, found is false when no
+item matches.
+*/
+func Find[T any](v Validator, items []T) (item T, found bool, err error) {
+	match, err := newMatcher[T](v)
+	if err != nil {
+		return item, false, err
+	}
+	for _, candidate := range items {
+		isValid, err := match(candidate)
+		if err != nil {
+			return item, false, err
+		}
+		if isValid {
+			return candidate, true, nil
+		}
+	}
+	return item, false, nil
+}
+
+// newMatcher compiles the condition once when T is a struct or map type, or
+// a pointer to one, and validates item by item otherwise.
+func newMatcher[T any](v Validator) (func(item T) (bool, error), error) {
+	rType := reflect.TypeOf((*T)(nil)).Elem()
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	switch rType.Kind() {
+	case reflect.Struct, reflect.Map:
+		plan, err := v.Compile(rType)
+		if err != nil {
+			return nil, err
+		}
+		return func(item T) (bool, error) {
+			return plan.Validate(item)
+		}, nil
+	}
+	return func(item T) (bool, error) {
+		return v.ValidateStruct(item)
+	}, nil
+}
+
+/*
+RegisterConverter
+-----------------------------------------------------------------------
+makes every validator compare the values of T as converter converts
+them, e.g. a money type as its amount in cents or an ID type as its
+text. The result is converted again, so it may be a number, a string,
+a bool, a time.Time or any type a validator can compare, and nil is a
+null. A converter error fails the validation as a *TypeMismatchError.
+
+Converters replace the default conversion of T, which compares values
+by their kind, so a `type Status string` compares as a string and an
+int32 as an integer, and unwraps driver.Valuer values such as
+sql.NullString, encoding.TextMarshaler and fmt.Stringer.
+
+	deepvalidator.RegisterConverter(func(m Money) (interface{}, error) {
+		return m.Cents(), nil
+	})
+*/
+func RegisterConverter[T any](converter func(value T) (interface{}, error)) {
+	validators.RegisterConverter(reflect.TypeOf((*T)(nil)).Elem(), func(value interface{}) (interface{}, error) {
+		return converter(value.(T))
+	})
+}
@@ -0,0 +1,528 @@
+package jsonlogic
+
+import (
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strconv"
+	"strings"
+)
+
+const (
+	operatorAnd  = "and"
+	operatorOr   = "or"
+	operatorNot  = "!"
+	operatorVar  = "var"
+	operatorIn   = "in"
+	operatorSome = "some"
+	operatorAll  = "all"
+	operatorNone = "none"
+)
+
+var comparisonOperators = map[string]string{
+	operators.OperatorEqual:            "==",
+	operators.OperatorNotEqual:         "!=",
+	operators.OperatorLessThan:         "<",
+	operators.OperatorLessThanEqual:    "<=",
+	operators.OperatorGreaterThan:      ">",
+	operators.OperatorGreaterThanEqual: ">=",
+}
+
+var ruleOperators = map[string]string{
+	"==":  operators.OperatorEqual,
+	"===": operators.OperatorEqual,
+	"!=":  operators.OperatorNotEqual,
+	"!==": operators.OperatorNotEqual,
+	"<":   operators.OperatorLessThan,
+	"<=":  operators.OperatorLessThanEqual,
+	">":   operators.OperatorGreaterThan,
+	">=":  operators.OperatorGreaterThanEqual,
+}
+
+// flippedOperators gives the operator of a comparison whose operands are
+// swapped, `5 < x` is `x > 5`.
+var flippedOperators = map[string]string{
+	operators.OperatorEqual:            operators.OperatorEqual,
+	operators.OperatorNotEqual:         operators.OperatorNotEqual,
+	operators.OperatorLessThan:         operators.OperatorGreaterThan,
+	operators.OperatorLessThanEqual:    operators.OperatorGreaterThanEqual,
+	operators.OperatorGreaterThan:      operators.OperatorLessThan,
+	operators.OperatorGreaterThanEqual: operators.OperatorLessThanEqual,
+}
+
+var quantifierOperators = map[string]string{
+	quantifiers.QuantifierAny:  operatorSome,
+	quantifiers.QuantifierAll:  operatorAll,
+	quantifiers.QuantifierNone: operatorNone,
+}
+
+// ToRule converts condition into a JSON Logic rule made of plain maps and
+// slices, ready for json.Marshal. A group mixing && and || nests an `and` or
+// `or` per run of the same operator. An empty condition gives the rule `true`.
+// Regex matches, `len` and exists have no JSON Logic equivalent and give an
+// error, a var is null when it is missing so is null compares it with null.
+func ToRule(condition structs.Condition) (interface{}, error) {
+	if condition.IsEmpty() {
+		return true, nil
+	}
+	e := &exporter{}
+	if condition.Quantifier == "" && len(condition.Conditions) > 0 && !condition.Negate {
+		return e.sequence(condition.Conditions)
+	}
+	return e.term(&condition)
+}
+
+// exporter tracks the collections of the enclosing quantifiers, a `var`
+// inside `some`, `all` or `none` is relative to the element.
+type exporter struct {
+	collections []string
+}
+
+// sequence translates the terms of a group, `a || b && c` becomes
+// `{"and": [{"or": [a, b]}, c]}`.
+func (e *exporter) sequence(conditions []*structs.Condition) (map[string]interface{}, error) {
+	operator, terms, err := structs.Fold(conditions, e.term, joinTerms)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return joinTerms(operator, terms), nil
+}
+
+// joinTerms joins the terms of a run with the operator of the run.
+func joinTerms(operator string, terms []map[string]interface{}) map[string]interface{} {
+	key := operatorAnd
+	if operator == logicaloperators.LogicalOperatorOr {
+		key = operatorOr
+	}
+	operands := make([]interface{}, len(terms))
+	for i, term := range terms {
+		operands[i] = term
+	}
+	return map[string]interface{}{key: operands}
+}
+
+func (e *exporter) term(condition *structs.Condition) (term map[string]interface{}, err error) {
+	switch {
+	case condition.Quantifier != "":
+		term, err = e.quantifier(condition)
+	case len(condition.Conditions) > 0:
+		term, err = e.sequence(condition.Conditions)
+	case condition.Attribute != nil:
+		term, err = e.comparison(condition.Attribute)
+	default:
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "attribute")
+	}
+	if err != nil || !condition.Negate {
+		return term, err
+	}
+	return map[string]interface{}{operatorNot: []interface{}{term}}, nil
+}
+
+func (e *exporter) quantifier(condition *structs.Condition) (map[string]interface{}, error) {
+	operator, ok := quantifierOperators[condition.Quantifier]
+	if !ok {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("quantifier %s", condition.Quantifier))
+	}
+	collection, err := e.variable(condition.Collection)
+	if err != nil {
+		return nil, err
+	}
+	e.collections = append(e.collections, condition.Collection)
+	rule, err := e.sequence(condition.Conditions)
+	e.collections = e.collections[:len(e.collections)-1]
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{operator: []interface{}{collection, rule}}, nil
+}
+
+func (e *exporter) comparison(attribute *structs.Attribute) (map[string]interface{}, error) {
+	if attribute.Function != "" {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("function %s", attribute.Function))
+	}
+	variable, err := e.variable(attribute.Name)
+	if err != nil {
+		return nil, err
+	}
+	// JSON Logic has no dates, they stay strings
+	valueType := attribute.Type
+	if valueType == valuetypes.Date {
+		valueType = valuetypes.Alphanumeric
+	}
+
+	switch attribute.Operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		values := make([]interface{}, len(attribute.Values))
+		for i, value := range attribute.Values {
+			values[i] = structgen.TypedValue(value, valueType)
+		}
+		rule := map[string]interface{}{operatorIn: []interface{}{variable, values}}
+		if attribute.Operator == operators.OperatorNotIn {
+			return map[string]interface{}{operatorNot: []interface{}{rule}}, nil
+		}
+		return rule, nil
+	case operators.OperatorContains:
+		return map[string]interface{}{operatorIn: []interface{}{attribute.Value, variable}}, nil
+	case operators.OperatorIsNull:
+		return map[string]interface{}{comparisonOperators[operators.OperatorEqual]: []interface{}{variable, nil}}, nil
+	case operators.OperatorIsNotNull:
+		return map[string]interface{}{comparisonOperators[operators.OperatorNotEqual]: []interface{}{variable, nil}}, nil
+	}
+
+	operator, ok := comparisonOperators[attribute.Operator]
+	if !ok {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", attribute.Operator))
+	}
+	var value interface{}
+	if attribute.Type == valuetypes.Field {
+		if value, err = e.variable(attribute.Value); err != nil {
+			return nil, err
+		}
+	} else {
+		value = structgen.TypedValue(attribute.Value, valueType)
+	}
+	return map[string]interface{}{operator: []interface{}{variable, value}}, nil
+}
+
+// variable builds the `var` of path, relative to the element of the innermost
+// quantifier.
+func (e *exporter) variable(path string) (map[string]interface{}, error) {
+	if len(e.collections) > 0 {
+		collection := e.collections[len(e.collections)-1]
+		switch {
+		case path == collection:
+			path = ""
+		case strings.HasPrefix(path, collection+"."):
+			path = path[len(collection)+1:]
+		default:
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("path %q inside quantifier of %q", path, collection))
+		}
+	}
+	return map[string]interface{}{operatorVar: path}, nil
+}
+
+// FromRule converts a JSON Logic rule, as decoded by json.Unmarshal, into a
+// condition shaped like the ones GenerateCondition builds. It understands
+// `and`, `or`, `!`, the comparisons with a `var` on at least one side, the
+// three operand `<` and `<=`, `in` on an array or a string and `some`, `all`
+// and `none`. The rule `true` gives an empty condition.
+func FromRule(rule interface{}) (structs.Condition, error) {
+	if isTrue, ok := rule.(bool); ok && isTrue {
+		return structs.Condition{Attribute: &structs.Attribute{}}, nil
+	}
+	conditions, err := ruleSequence(rule, "", "")
+	if err != nil {
+		return structs.Condition{}, err
+	}
+	return structs.Condition{Conditions: conditions}, nil
+}
+
+// ruleOperator splits rule into its single operator and its operands, a
+// single operand may be given without the array.
+func ruleOperator(rule interface{}) (string, []interface{}, error) {
+	document, ok := rule.(map[string]interface{})
+	if !ok || len(document) != 1 {
+		return "", nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, fmt.Sprintf("rule with a single operator, got %v", rule))
+	}
+	for operator, operand := range document {
+		if operands, ok := operand.([]interface{}); ok {
+			return operator, operands, nil
+		}
+		return operator, []interface{}{operand}, nil
+	}
+	return "", nil, nil
+}
+
+// ruleSequence reads rule as the terms of a sequence, flattening `and` and
+// `or`. A path inside a quantifier is prefixed with its collection, element
+// is the collection a `var` of "" refers to.
+func ruleSequence(rule interface{}, prefix, element string) ([]*structs.Condition, error) {
+	operator, operands, err := ruleOperator(rule)
+	if err != nil {
+		return nil, err
+	}
+	if operator != operatorAnd && operator != operatorOr {
+		condition, err := ruleTerm(rule, prefix, element)
+		if err != nil {
+			return nil, err
+		}
+		return []*structs.Condition{condition}, nil
+	}
+	if len(operands) == 0 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "operand of "+operator)
+	}
+	logicalOperator := logicaloperators.LogicalOperatorAnd
+	if operator == operatorOr {
+		logicalOperator = logicaloperators.LogicalOperatorOr
+	}
+	conditions := make([]*structs.Condition, len(operands))
+	for i, operand := range operands {
+		if conditions[i], err = ruleTerm(operand, prefix, element); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			conditions[i].Operator = logicalOperator
+		}
+	}
+	return conditions, nil
+}
+
+func ruleTerm(rule interface{}, prefix, element string) (*structs.Condition, error) {
+	operator, operands, err := ruleOperator(rule)
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case operatorAnd, operatorOr:
+		conditions, err := ruleSequence(rule, prefix, element)
+		if err != nil {
+			return nil, err
+		}
+		return group(conditions), nil
+	case operatorNot:
+		if len(operands) != 1 {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "single operand of "+operatorNot)
+		}
+		condition, err := ruleTerm(operands[0], prefix, element)
+		if err != nil {
+			return nil, err
+		}
+		if attribute := condition.Attribute; !condition.Negate && len(condition.Conditions) == 0 && attribute != nil && attribute.Operator == operators.OperatorIn {
+			attribute.Operator = operators.OperatorNotIn
+			return condition, nil
+		}
+		condition.Negate = !condition.Negate
+		return condition, nil
+	case operatorSome, operatorAll, operatorNone:
+		return ruleQuantifier(operator, operands, prefix, element)
+	case operatorIn:
+		return ruleIn(operands, prefix, element)
+	}
+	comparisonOperator, ok := ruleOperators[operator]
+	if !ok {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("JSON Logic operator %q", operator))
+	}
+	if len(operands) == 3 && (operator == "<" || operator == "<=") {
+		return ruleBetween(comparisonOperator, operands, prefix, element)
+	}
+	if len(operands) != 2 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "two operands of "+operator)
+	}
+	return ruleComparison(comparisonOperator, operands[0], operands[1], prefix, element)
+}
+
+func group(conditions []*structs.Condition) *structs.Condition {
+	if len(conditions) == 1 {
+		conditions[0].Operator = ""
+		return conditions[0]
+	}
+	return &structs.Condition{Conditions: conditions}
+}
+
+// ruleComparison reads a comparison of a var with a value or another var, a
+// value on the left swaps the operands.
+func ruleComparison(operator string, left, right interface{}, prefix, element string) (*structs.Condition, error) {
+	name, isName, err := ruleVariable(left, prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	if !isName {
+		name, isName, err = ruleVariable(right, prefix, element)
+		if err != nil {
+			return nil, err
+		}
+		if !isName {
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, "comparison without a var")
+		}
+		operator, left, right = flippedOperators[operator], right, left
+	}
+	attribute := &structs.Attribute{
+		Name:     name,
+		Operator: operator,
+	}
+	if right == nil {
+		// a var is null when it is missing as well, like is null
+		switch operator {
+		case operators.OperatorEqual:
+			attribute.Operator = operators.OperatorIsNull
+			return &structs.Condition{Attribute: attribute}, nil
+		case operators.OperatorNotEqual:
+			attribute.Operator = operators.OperatorIsNotNull
+			return &structs.Condition{Attribute: attribute}, nil
+		}
+	}
+	reference, isReference, err := ruleVariable(right, prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	if isReference {
+		attribute.Value, attribute.Type = reference, valuetypes.Field
+	} else if attribute.Value, attribute.Type, err = literal(right); err != nil {
+		return nil, err
+	}
+	return &structs.Condition{Attribute: attribute}, nil
+}
+
+// ruleBetween reads `{"<": [1, {"var": "x"}, 10]}` as `x > 1 && x < 10`.
+func ruleBetween(operator string, operands []interface{}, prefix, element string) (*structs.Condition, error) {
+	low, err := ruleComparison(operator, operands[0], operands[1], prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	high, err := ruleComparison(operator, operands[1], operands[2], prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	high.Operator = logicaloperators.LogicalOperatorAnd
+	return &structs.Condition{Conditions: []*structs.Condition{low, high}}, nil
+}
+
+// ruleIn reads `in` on an array of values as an in list and `in` of a string
+// as a contains comparison.
+func ruleIn(operands []interface{}, prefix, element string) (*structs.Condition, error) {
+	if len(operands) != 2 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "two operands of "+operatorIn)
+	}
+	if name, isName, err := ruleVariable(operands[1], prefix, element); err != nil || isName {
+		if err != nil {
+			return nil, err
+		}
+		value, isString := operands[0].(string)
+		if !isString {
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("%s of %v in a var", operatorIn, operands[0]))
+		}
+		return &structs.Condition{
+			Attribute: &structs.Attribute{
+				Name:     name,
+				Operator: operators.OperatorContains,
+				Value:    value,
+				Type:     stringType(value),
+			},
+		}, nil
+	}
+	name, isName, err := ruleVariable(operands[0], prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	values, isList := operands[1].([]interface{})
+	if !isName || !isList || len(values) == 0 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operatorIn+" other than a var in a value list or a string in a var")
+	}
+	attribute := &structs.Attribute{
+		Name:     name,
+		Operator: operators.OperatorIn,
+	}
+	for i, value := range values {
+		text, valueType, err := literal(value)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			attribute.Type = valueType
+		} else if attribute.Type != valueType {
+			attribute.Type = ""
+		}
+		attribute.Values = append(attribute.Values, text)
+	}
+	return &structs.Condition{Attribute: attribute}, nil
+}
+
+func ruleQuantifier(operator string, operands []interface{}, prefix, element string) (*structs.Condition, error) {
+	if len(operands) != 2 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "two operands of "+operator)
+	}
+	collection, isName, err := ruleVariable(operands[0], prefix, element)
+	if err != nil {
+		return nil, err
+	}
+	if !isName {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operator+" over something other than a var")
+	}
+	conditions, err := ruleSequence(operands[1], collection+".", collection)
+	if err != nil {
+		return nil, err
+	}
+	quantifier := quantifiers.QuantifierAny
+	switch operator {
+	case operatorAll:
+		quantifier = quantifiers.QuantifierAll
+	case operatorNone:
+		quantifier = quantifiers.QuantifierNone
+	}
+	return &structs.Condition{
+		Quantifier: quantifier,
+		Collection: collection,
+		Conditions: conditions,
+	}, nil
+}
+
+// ruleVariable returns the attribute path operand refers to when it is a
+// `var`. A `var` with a default value has no equivalent.
+func ruleVariable(operand interface{}, prefix, element string) (string, bool, error) {
+	document, ok := operand.(map[string]interface{})
+	if !ok {
+		return "", false, nil
+	}
+	operator, operands, err := ruleOperator(document)
+	if err != nil {
+		return "", false, err
+	}
+	if operator != operatorVar {
+		return "", false, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("JSON Logic operator %q as an operand", operator))
+	}
+	if len(operands) != 1 {
+		return "", false, fmt.Errorf(errormessages.ErrorMessageUnsupported, "var with a default value")
+	}
+	var path string
+	switch val := operands[0].(type) {
+	case string:
+		path = val
+	case float64:
+		path = strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return "", false, fmt.Errorf(errormessages.ErrorMessageInvalidType, "string var")
+	}
+	if path == "" {
+		if element == "" {
+			return "", false, fmt.Errorf(errormessages.ErrorMessageUnsupported, "var of the whole data")
+		}
+		return element, true, nil
+	}
+	return prefix + path, true, nil
+}
+
+// literal formats a JSON value as condition value text and the type the
+// query parser would give it.
+func literal(value interface{}) (string, valuetypes.ValueType, error) {
+	switch val := value.(type) {
+	case string:
+		return val, stringType(val), nil
+	case bool:
+		return strconv.FormatBool(val), valuetypes.Alphanumeric, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), valuetypes.Numeric, nil
+	case int:
+		return strconv.Itoa(val), valuetypes.Numeric, nil
+	case int64:
+		return strconv.FormatInt(val, 10), valuetypes.Numeric, nil
+	}
+	return "", "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("value %v of type %T", value, value))
+}
+
+// stringType is the type of a string value, none when the query parser would
+// read it as a number unquoted. Dates stay dates as JSON Logic can only hold
+// them as strings.
+func stringType(value string) valuetypes.ValueType {
+	if valueType := structgen.ValueType(value); valueType != valuetypes.Numeric {
+		return valueType
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// marshal encodes a rule without escaping the comparison operators.
+func marshal(rule interface{}) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(rule)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func TestToRule(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		legacyPrecedence bool
+		want             string
+		wantErr          bool
+	}{
+		{
+			name:  "Normal case - comparisons",
+			query: `status = paid && amount >= 10.5 && id != 1 && code = "7" && created_at < 2024-01-02T03:04:05Z`,
+			want:  `{"and":[{"==":[{"var":"status"},"paid"]},{">=":[{"var":"amount"},10.5]},{"!=":[{"var":"id"},1]},{"==":[{"var":"code"},"7"]},{"<":[{"var":"created_at"},"2024-01-02T03:04:05Z"]}]}`,
+		},
+		{
+			name:             "Normal case - left to right fold and negation",
+			query:            `a = 1 || b = 2 && !(c = 3 || d = 4)`,
+			legacyPrecedence: true,
+			want:             `{"and":[{"or":[{"==":[{"var":"a"},1]},{"==":[{"var":"b"},2]}]},{"!":[{"or":[{"==":[{"var":"c"},3]},{"==":[{"var":"d"},4]}]}]}]}`,
+		},
+		{
+			name:  "Normal case - and binds tighter than or",
+			query: `a = 1 || b = 2 && c = 3`,
+			want:  `{"or":[{"==":[{"var":"a"},1]},{"and":[{"==":[{"var":"b"},2]},{"==":[{"var":"c"},3]}]}]}`,
+		},
+		{
+			name:  "Normal case - lists, contains and references",
+			query: `partner in (bca, bni) && id not in (1, 2) && note |= ref && debit > $credit`,
+			want:  `{"and":[{"in":[{"var":"partner"},["bca","bni"]]},{"!":[{"in":[{"var":"id"},[1,2]]}]},{"in":["ref",{"var":"note"}]},{">":[{"var":"debit"},{"var":"credit"}]}]}`,
+		},
+		{
+			name:  "Normal case - quantifiers",
+			query: `any(Items, Items.Amount > 1000 && all(Items.Tags, Items.Tags != x)) && none(Codes, Codes = y)`,
+			want:  `{"and":[{"some":[{"var":"Items"},{"and":[{">":[{"var":"Amount"},1000]},{"all":[{"var":"Tags"},{"!=":[{"var":""},"x"]}]}]}]},{"none":[{"var":"Codes"},{"==":[{"var":""},"y"]}]}]}`,
+		},
+		{
+			name:  "Normal case - nulls",
+			query: `sender_type is null || partner is not null`,
+			want:  `{"or":[{"==":[{"var":"sender_type"},null]},{"!=":[{"var":"partner"},null]}]}`,
+		},
+		{
+			name:  "Normal case - empty condition",
+			query: ``,
+			want:  `true`,
+		},
+		{
+			name:    "Error case - regex",
+			query:   `name |~ "^re"`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - function",
+			query:   `len(Items) > 1`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - exists",
+			query:   `exists(sender_type)`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - path outside of the collection",
+			query:   `any(Items, Total > 1)`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := structgen.StructGen{LegacyPrecedence: tt.legacyPrecedence}
+			condition, err := gen.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			rule, err := ToRule(condition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := marshal(rule); got != tt.want {
+				t.Errorf("ToRule() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Normal case - comparisons",
+			rule: `{"and":[{"==":[{"var":"status"},"paid"]},{"<":[100,{"var":"amount"}]},{"===":[{"var":"code"},"7"]},{"!==":[{"var":"ok"},true]}]}`,
+			want: `status = paid && amount > 100 && code = "7" && ok != true`,
+		},
+		{
+			name: "Normal case - between, negation and single operand",
+			rule: `{"or":[{"<=":[1,{"var":"x"},10]},{"!":{"or":[{"==":[{"var":"a"},1]},{"==":[{"var":"b"},2]}]}},{"!":[{"in":[{"var":"id"},[1,"2"]]}]}]}`,
+			want: `(x >= 1 && x <= 10) || !(a = 1 || b = 2) || id not in ("1", "2")`,
+		},
+		{
+			name: "Normal case - contains, references and quantifiers",
+			rule: `{"and":[{"in":["ref",{"var":"note"}]},{">":[{"var":"debit"},{"var":"credit"}]},{"some":[{"var":"Items"},{"none":[{"var":"Tags"},{"==":[{"var":""},"x"]}]}]}]}`,
+			want: `note |= ref && debit > $credit && any(Items, none(Items.Tags, Items.Tags = x))`,
+		},
+		{
+			name: "Normal case - nulls",
+			rule: `{"and":[{"==":[{"var":"a"},null]},{"!==":[null,{"var":"b"}]}]}`,
+			want: `a is null && b is not null`,
+		},
+		{
+			name: "Normal case - true",
+			rule: `true`,
+			want: ``,
+		},
+		{
+			name:    "Error case - unknown operator",
+			rule:    `{"cat":["a","b"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - var with default",
+			rule:    `{"==":[{"var":["a", 1]},1]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - comparison without var",
+			rule:    `{"==":[1,1]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - arithmetic operand",
+			rule:    `{">":[{"+":[{"var":"a"},1]},2]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - whole data var",
+			rule:    `{"==":[{"var":""},1]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule interface{}
+			if err := json.Unmarshal([]byte(tt.rule), &rule); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got, err := FromRule(rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if printed := structgen.Print(got); !tt.wantErr && printed != tt.want {
+				t.Errorf("FromRule() = %v, want %v", printed, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromRule_RoundTrip(t *testing.T) {
+	queries := []string{
+		`status = paid && amount >= 10.5 && code = "7" && created_at < 2024-01-02T03:04:05Z`,
+		`(a = 1 || b = 2) && !(c = x && d = y) && e in (1, 2) && f not in (p, q)`,
+		`note |= ref || debit > $credit`,
+		`any(Items, Items.Amount > 1000 && all(Items.Tags, Items.Tags != x)) && !none(Codes, Codes = y)`,
+	}
+	var gen structgen.StructGen
+	for _, query := range queries {
+		condition, err := gen.GenerateCondition(query)
+		if err != nil {
+			t.Fatalf("GenerateCondition(%q) error = %v", query, err)
+		}
+		rule, err := ToRule(condition)
+		if err != nil {
+			t.Fatalf("ToRule(%q) error = %v", query, err)
+		}
+		encoded, _ := json.Marshal(rule)
+		var decoded interface{}
+		_ = json.Unmarshal(encoded, &decoded)
+		got, err := FromRule(decoded)
+		if err != nil {
+			t.Fatalf("FromRule(%q) error = %v", query, err)
+		}
+		if !reflect.DeepEqual(got, condition) {
+			t.Errorf("FromRule(ToRule(%q)) = %v", query, structgen.Print(got))
+		}
+	}
+}
+
+func TestFromRule_Validate(t *testing.T) {
+	type item struct {
+		Amount int
+	}
+	order := struct {
+		Status string
+		Total  float64
+		Items  []item
+	}{
+		Status: "paid",
+		Total:  150,
+		Items:  []item{{Amount: 50}, {Amount: 1500}},
+	}
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{`{"and":[{"==":[{"var":"Status"},"paid"]},{">":[{"var":"Total"},100]}]}`, true},
+		{`{"and":[{"==":[{"var":"Status"},"paid"]},{">":[{"var":"Total"},200]}]}`, false},
+		{`{"some":[{"var":"Items"},{">=":[{"var":"Amount"},1000]}]}`, true},
+		{`{"all":[{"var":"Items"},{">=":[{"var":"Amount"},1000]}]}`, false},
+	}
+	for _, tt := range tests {
+		var rule interface{}
+		_ = json.Unmarshal([]byte(tt.rule), &rule)
+		condition, err := FromRule(rule)
+		if err != nil {
+			t.Fatalf("FromRule(%s) error = %v", tt.rule, err)
+		}
+		got, err := validators.NewConditionValidator(&condition).Validate(order)
+		if err != nil || got != tt.want {
+			t.Errorf("Validate(%s) = %v, %v, want %v", tt.rule, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,548 @@
+package mongogen
+
+import (
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	operatorAnd       = "$and"
+	operatorOr        = "$or"
+	operatorNor       = "$nor"
+	operatorNot       = "$not"
+	operatorExpr      = "$expr"
+	operatorIn        = "$in"
+	operatorNotIn     = "$nin"
+	operatorRegex     = "$regex"
+	operatorOptions   = "$options"
+	operatorElemMatch = "$elemMatch"
+	operatorExists    = "$exists"
+)
+
+// fieldPathPrefix marks a string in an aggregation expression as a field path.
+const fieldPathPrefix = "$"
+
+var comparisonOperators = map[string]string{
+	operators.OperatorEqual:            "$eq",
+	operators.OperatorNotEqual:         "$ne",
+	operators.OperatorLessThan:         "$lt",
+	operators.OperatorLessThanEqual:    "$lte",
+	operators.OperatorGreaterThan:      "$gt",
+	operators.OperatorGreaterThanEqual: "$gte",
+}
+
+var filterOperators = map[string]string{
+	"$eq":  operators.OperatorEqual,
+	"$ne":  operators.OperatorNotEqual,
+	"$lt":  operators.OperatorLessThan,
+	"$lte": operators.OperatorLessThanEqual,
+	"$gt":  operators.OperatorGreaterThan,
+	"$gte": operators.OperatorGreaterThanEqual,
+}
+
+// ToFilter converts condition into a MongoDB filter document made of plain
+// maps and slices, e.g. `{"$and": [{"id": {"$eq": 1}}, ...]}`. Values get the
+// Go type of the literal type the query parser inferred. A group mixing && and
+// || nests an `$and` or `$or` per run of the same operator. An empty condition
+// gives an empty filter. `any` and `none` become `$elemMatch`, the other quantifiers
+// and the functions have no translation and give an error.
+func ToFilter(condition structs.Condition) (map[string]interface{}, error) {
+	if condition.IsEmpty() {
+		return map[string]interface{}{}, nil
+	}
+	e := &exporter{}
+	if condition.Quantifier == "" && len(condition.Conditions) > 0 && !condition.Negate {
+		return e.sequence(condition.Conditions)
+	}
+	return e.term(&condition)
+}
+
+// exporter tracks the collections of the enclosing quantifiers, the paths
+// inside an `$elemMatch` are relative to its element.
+type exporter struct {
+	collections []string
+}
+
+// sequence translates the terms of a group, `a || b && c` becomes
+// `{"$and": [{"$or": [a, b]}, c]}`.
+func (e *exporter) sequence(conditions []*structs.Condition) (map[string]interface{}, error) {
+	operator, terms, err := structs.Fold(conditions, e.term, joinTerms)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return joinTerms(operator, terms), nil
+}
+
+// joinTerms joins the terms of a run with the operator of the run.
+func joinTerms(operator string, terms []map[string]interface{}) map[string]interface{} {
+	key := operatorAnd
+	if operator == logicaloperators.LogicalOperatorOr {
+		key = operatorOr
+	}
+	operands := make([]interface{}, len(terms))
+	for i, term := range terms {
+		operands[i] = term
+	}
+	return map[string]interface{}{key: operands}
+}
+
+func (e *exporter) term(condition *structs.Condition) (term map[string]interface{}, err error) {
+	switch {
+	case condition.Quantifier != "":
+		term, err = e.quantifier(condition)
+	case len(condition.Conditions) > 0:
+		term, err = e.sequence(condition.Conditions)
+	case condition.Attribute != nil:
+		term, err = e.comparison(condition.Attribute)
+	default:
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "attribute")
+	}
+	if err != nil || !condition.Negate {
+		return term, err
+	}
+	return map[string]interface{}{operatorNor: []interface{}{term}}, nil
+}
+
+func (e *exporter) quantifier(condition *structs.Condition) (map[string]interface{}, error) {
+	switch condition.Quantifier {
+	case quantifiers.QuantifierAny, quantifiers.QuantifierNone:
+	default:
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("quantifier %s", condition.Quantifier))
+	}
+	collection, err := e.path(condition.Collection)
+	if err != nil {
+		return nil, err
+	}
+	e.collections = append(e.collections, condition.Collection)
+	match, err := e.sequence(condition.Conditions)
+	e.collections = e.collections[:len(e.collections)-1]
+	if err != nil {
+		return nil, err
+	}
+	term := map[string]interface{}{collection: map[string]interface{}{operatorElemMatch: match}}
+	if condition.Quantifier == quantifiers.QuantifierNone {
+		return map[string]interface{}{operatorNor: []interface{}{term}}, nil
+	}
+	return term, nil
+}
+
+func (e *exporter) comparison(attribute *structs.Attribute) (map[string]interface{}, error) {
+	if attribute.Function != "" {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("function %s", attribute.Function))
+	}
+	name, err := e.path(attribute.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var value map[string]interface{}
+	switch attribute.Operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		values := make([]interface{}, len(attribute.Values))
+		for i, val := range attribute.Values {
+			values[i] = structgen.TypedValue(val, attribute.Type)
+		}
+		operator := operatorIn
+		if attribute.Operator == operators.OperatorNotIn {
+			operator = operatorNotIn
+		}
+		value = map[string]interface{}{operator: values}
+	case operators.OperatorContains:
+		value = map[string]interface{}{operatorRegex: regexp.QuoteMeta(attribute.Value)}
+	case operators.OperatorContainsRegexMatch:
+		value = map[string]interface{}{operatorRegex: attribute.Value}
+	case operators.OperatorExists:
+		value = map[string]interface{}{operatorExists: true}
+	case operators.OperatorIsNull:
+		value = map[string]interface{}{comparisonOperators[operators.OperatorEqual]: nil}
+	case operators.OperatorIsNotNull:
+		value = map[string]interface{}{comparisonOperators[operators.OperatorNotEqual]: nil}
+	default:
+		operator, ok := comparisonOperators[attribute.Operator]
+		if !ok {
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", attribute.Operator))
+		}
+		if attribute.Type == valuetypes.Field {
+			if len(e.collections) > 0 {
+				return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, "field reference inside a quantifier")
+			}
+			return map[string]interface{}{
+				operatorExpr: map[string]interface{}{
+					operator: []interface{}{fieldPathPrefix + name, fieldPathPrefix + attribute.Value},
+				},
+			}, nil
+		}
+		value = map[string]interface{}{operator: structgen.TypedValue(attribute.Value, attribute.Type)}
+	}
+	return map[string]interface{}{name: value}, nil
+}
+
+// path makes name relative to the element of the innermost quantifier.
+func (e *exporter) path(name string) (string, error) {
+	if len(e.collections) == 0 {
+		return name, nil
+	}
+	collection := e.collections[len(e.collections)-1]
+	if !strings.HasPrefix(name, collection+".") {
+		return "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("path %q inside quantifier of %q", name, collection))
+	}
+	return name[len(collection)+1:], nil
+}
+
+// FromFilter converts a MongoDB filter document back into a condition shaped
+// like the ones GenerateCondition builds. The fields of a document are read
+// in the order of their names. It understands the logical operators, the
+// comparison operators, `$in`, `$nin`, `$regex` without options, `$not`,
+// `$elemMatch` and `$expr` comparing two fields.
+func FromFilter(filter map[string]interface{}) (structs.Condition, error) {
+	if len(filter) == 0 {
+		return structs.Condition{Attribute: &structs.Attribute{}}, nil
+	}
+	conditions, err := sequence(filter, "")
+	if err != nil {
+		return structs.Condition{}, err
+	}
+	return structs.Condition{Conditions: conditions}, nil
+}
+
+// sequence reads the terms of a filter document, the paths of its fields are
+// prefixed with the collection of the enclosing `$elemMatch`.
+func sequence(filter map[string]interface{}, prefix string) ([]*structs.Condition, error) {
+	if len(filter) == 1 {
+		for key, value := range filter {
+			if key == operatorAnd || key == operatorOr {
+				return logicalSequence(key, value, prefix)
+			}
+		}
+	}
+	var conditions []*structs.Condition
+	for _, key := range sortedKeys(filter) {
+		terms, err := keyTerms(key, filter[key], prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range terms {
+			if len(conditions) > 0 {
+				term.Operator = logicaloperators.LogicalOperatorAnd
+			}
+			conditions = append(conditions, term)
+		}
+	}
+	return conditions, nil
+}
+
+func logicalSequence(key string, value interface{}, prefix string) ([]*structs.Condition, error) {
+	filters, err := filterList(key, value)
+	if err != nil {
+		return nil, err
+	}
+	operator := logicaloperators.LogicalOperatorAnd
+	if key == operatorOr {
+		operator = logicaloperators.LogicalOperatorOr
+	}
+	conditions := make([]*structs.Condition, len(filters))
+	for i, filter := range filters {
+		if conditions[i], err = term(filter, prefix); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			conditions[i].Operator = operator
+		}
+	}
+	return conditions, nil
+}
+
+// term reads a filter document as a single condition, a group when it has
+// more than one term.
+func term(filter map[string]interface{}, prefix string) (*structs.Condition, error) {
+	conditions, err := sequence(filter, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return group(conditions), nil
+}
+
+func group(conditions []*structs.Condition) *structs.Condition {
+	if len(conditions) == 1 {
+		conditions[0].Operator = ""
+		return conditions[0]
+	}
+	return &structs.Condition{Conditions: conditions}
+}
+
+func keyTerms(key string, value interface{}, prefix string) ([]*structs.Condition, error) {
+	switch key {
+	case operatorAnd, operatorOr:
+		conditions, err := logicalSequence(key, value, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return []*structs.Condition{group(conditions)}, nil
+	case operatorNor:
+		conditions, err := logicalSequence(operatorOr, value, prefix)
+		if err != nil {
+			return nil, err
+		}
+		negated := group(conditions)
+		negated.Negate = !negated.Negate
+		return []*structs.Condition{negated}, nil
+	case operatorExpr:
+		if prefix != "" {
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operatorExpr+" inside "+operatorElemMatch)
+		}
+		condition, err := expression(value)
+		if err != nil {
+			return nil, err
+		}
+		return []*structs.Condition{condition}, nil
+	}
+	if strings.HasPrefix(key, "$") {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", key))
+	}
+	return fieldTerms(prefix+key, value)
+}
+
+// fieldTerms reads the condition on one field, a document of operators or a
+// value it has to equal.
+func fieldTerms(name string, value interface{}) ([]*structs.Condition, error) {
+	operatorsDoc, ok := value.(map[string]interface{})
+	if !ok {
+		return comparison(name, "$eq", value)
+	}
+	if len(operatorsDoc) == 0 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("embedded document on %q", name))
+	}
+	var conditions []*structs.Condition
+	for _, operator := range sortedKeys(operatorsDoc) {
+		operand := operatorsDoc[operator]
+		var terms []*structs.Condition
+		var err error
+		switch operator {
+		case operatorOptions:
+			if options, ok := operand.(string); !ok || options != "" {
+				return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operatorOptions)
+			}
+			continue
+		case operatorNot:
+			notDoc, ok := operand.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "document operand of "+operatorNot)
+			}
+			if terms, err = fieldTerms(name, notDoc); err == nil {
+				negated := group(terms)
+				negated.Negate = !negated.Negate
+				terms = []*structs.Condition{negated}
+			}
+		case operatorElemMatch:
+			match, ok := operand.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "document operand of "+operatorElemMatch)
+			}
+			var matchConditions []*structs.Condition
+			if matchConditions, err = sequence(match, name+"."); err == nil {
+				terms = []*structs.Condition{{
+					Quantifier: quantifiers.QuantifierAny,
+					Collection: name,
+					Conditions: matchConditions,
+				}}
+			}
+		default:
+			if !strings.HasPrefix(operator, "$") {
+				return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("embedded document on %q", name))
+			}
+			terms, err = comparison(name, operator, operand)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range terms {
+			if len(conditions) > 0 {
+				term.Operator = logicaloperators.LogicalOperatorAnd
+			}
+			conditions = append(conditions, term)
+		}
+	}
+	return conditions, nil
+}
+
+func comparison(name, operator string, operand interface{}) ([]*structs.Condition, error) {
+	attribute := &structs.Attribute{
+		Name: name,
+	}
+	switch operator {
+	case operatorIn, operatorNotIn:
+		rValue := reflect.ValueOf(operand)
+		if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array || rValue.Len() == 0 {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "non-empty array operand of "+operator)
+		}
+		attribute.Operator = operators.OperatorIn
+		if operator == operatorNotIn {
+			attribute.Operator = operators.OperatorNotIn
+		}
+		for i := 0; i < rValue.Len(); i++ {
+			value, valueType, err := literal(rValue.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				attribute.Type = valueType
+			} else if attribute.Type != valueType {
+				attribute.Type = ""
+			}
+			attribute.Values = append(attribute.Values, value)
+		}
+	case operatorRegex:
+		pattern, ok := operand.(string)
+		if !ok {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "string operand of "+operatorRegex)
+		}
+		attribute.Operator, attribute.Value = operators.OperatorContainsRegexMatch, pattern
+		if text := unquoteMeta(pattern); regexp.QuoteMeta(text) == pattern {
+			attribute.Operator, attribute.Value = operators.OperatorContains, text
+		}
+		attribute.Type = stringType(attribute.Value)
+	case operatorExists:
+		exists, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "boolean operand of "+operatorExists)
+		}
+		attribute.Operator = operators.OperatorExists
+		return []*structs.Condition{{Attribute: attribute, Negate: !exists}}, nil
+	default:
+		filterOperator, ok := filterOperators[operator]
+		if !ok {
+			return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", operator))
+		}
+		if operand == nil {
+			// null matches a missing field as well, like is null
+			switch filterOperator {
+			case operators.OperatorEqual:
+				attribute.Operator = operators.OperatorIsNull
+				return []*structs.Condition{{Attribute: attribute}}, nil
+			case operators.OperatorNotEqual:
+				attribute.Operator = operators.OperatorIsNotNull
+				return []*structs.Condition{{Attribute: attribute}}, nil
+			}
+		}
+		value, valueType, err := literal(operand)
+		if err != nil {
+			return nil, err
+		}
+		attribute.Operator, attribute.Value, attribute.Type = filterOperator, value, valueType
+	}
+	return []*structs.Condition{{Attribute: attribute}}, nil
+}
+
+// expression reads an `$expr` comparing two fields, e.g.
+// `{"$eq": ["$Debit", "$TotalAmount"]}`.
+func expression(value interface{}) (*structs.Condition, error) {
+	expr, ok := value.(map[string]interface{})
+	if !ok || len(expr) != 1 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operatorExpr+" other than a comparison of two fields")
+	}
+	for operator, operands := range expr {
+		filterOperator, ok := filterOperators[operator]
+		paths, isList := operands.([]interface{})
+		if !ok || !isList || len(paths) != 2 {
+			break
+		}
+		name, isName := paths[0].(string)
+		reference, isReference := paths[1].(string)
+		if !isName || !isReference || !strings.HasPrefix(name, fieldPathPrefix) || !strings.HasPrefix(reference, fieldPathPrefix) {
+			break
+		}
+		return &structs.Condition{
+			Attribute: &structs.Attribute{
+				Name:     name[len(fieldPathPrefix):],
+				Operator: filterOperator,
+				Value:    reference[len(fieldPathPrefix):],
+				Type:     valuetypes.Field,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, operatorExpr+" other than a comparison of two fields")
+}
+
+// literal formats a filter value as condition value text and the type the
+// query parser would give it.
+func literal(value interface{}) (string, valuetypes.ValueType, error) {
+	switch val := value.(type) {
+	case string:
+		return val, stringType(val), nil
+	case bool:
+		return strconv.FormatBool(val), valuetypes.Alphanumeric, nil
+	case time.Time:
+		return val.Format(time.RFC3339Nano), valuetypes.Date, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(val), valuetypes.Numeric, nil
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), valuetypes.Numeric, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), valuetypes.Numeric, nil
+	}
+	return "", "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("value %v of type %T", value, value))
+}
+
+// stringType is the type of a string value, none when the query parser would
+// read it as another type unquoted.
+func stringType(value string) valuetypes.ValueType {
+	if structgen.ValueType(value) != valuetypes.Alphanumeric {
+		return ""
+	}
+	return valuetypes.Alphanumeric
+}
+
+// unquoteMeta removes the escaping regexp.QuoteMeta adds.
+func unquoteMeta(pattern string) string {
+	var builder strings.Builder
+	isEscaped := false
+	for _, char := range pattern {
+		if char == '\\' && !isEscaped {
+			isEscaped = true
+			continue
+		}
+		isEscaped = false
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+func sortedKeys(document map[string]interface{}) []string {
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func filterList(key string, value interface{}) ([]map[string]interface{}, error) {
+	rValue := reflect.ValueOf(value)
+	if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array || rValue.Len() == 0 {
+		return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "non-empty array operand of "+key)
+	}
+	filters := make([]map[string]interface{}, rValue.Len())
+	for i := range filters {
+		filter, ok := rValue.Index(i).Interface().(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(errormessages.ErrorMessageInvalidType, "document in "+key)
+		}
+		filters[i] = filter
+	}
+	return filters, nil
+}
@@ -0,0 +1,231 @@
+package mongogen
+
+import (
+	"encoding/json"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type document = map[string]interface{}
+
+type list = []interface{}
+
+func TestToFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		legacyPrecedence bool
+		want             document
+		wantErr          bool
+	}{
+		{
+			name:  "Normal case - typed values",
+			query: `id = 1 && amount >= 10.5 && status != paid && code = "7" && created_at < 2024-01-02T03:04:05Z`,
+			want: document{"$and": list{
+				document{"id": document{"$eq": int64(1)}},
+				document{"amount": document{"$gte": 10.5}},
+				document{"status": document{"$ne": "paid"}},
+				document{"code": document{"$eq": "7"}},
+				document{"created_at": document{"$lt": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}},
+			}},
+		},
+		{
+			name:             "Normal case - left to right fold",
+			query:            `a = 1 || b = 2 && (c = 3 || d = 4)`,
+			legacyPrecedence: true,
+			want: document{"$and": list{
+				document{"$or": list{
+					document{"a": document{"$eq": int64(1)}},
+					document{"b": document{"$eq": int64(2)}},
+				}},
+				document{"$or": list{
+					document{"c": document{"$eq": int64(3)}},
+					document{"d": document{"$eq": int64(4)}},
+				}},
+			}},
+		},
+		{
+			name:  "Normal case - and binds tighter than or",
+			query: `a = 1 || b = 2 && c = 3`,
+			want: document{"$or": list{
+				document{"a": document{"$eq": int64(1)}},
+				document{"$and": list{
+					document{"b": document{"$eq": int64(2)}},
+					document{"c": document{"$eq": int64(3)}},
+				}},
+			}},
+		},
+		{
+			name:  "Normal case - lists, regexes, negation and references",
+			query: `partner in (bca, bni) && !id not in (1, 2) && note |= "a.b" && name |~ "^re" && debit > $credit`,
+			want: document{"$and": list{
+				document{"partner": document{"$in": list{"bca", "bni"}}},
+				document{"$nor": list{document{"id": document{"$nin": list{int64(1), int64(2)}}}}},
+				document{"note": document{"$regex": `a\.b`}},
+				document{"name": document{"$regex": "^re"}},
+				document{"$expr": document{"$gt": list{"$debit", "$credit"}}},
+			}},
+		},
+		{
+			name:  "Normal case - quantifiers",
+			query: `any(Items, Items.Amount > 1000 && Items.Code = x) && none(Items, Items.Void = true)`,
+			want: document{"$and": list{
+				document{"Items": document{"$elemMatch": document{"$and": list{
+					document{"Amount": document{"$gt": int64(1000)}},
+					document{"Code": document{"$eq": "x"}},
+				}}}},
+				document{"$nor": list{document{"Items": document{"$elemMatch": document{"Void": document{"$eq": "true"}}}}}},
+			}},
+		},
+		{
+			name:  "Normal case - null and exists",
+			query: `exists(a.b) && c is null && d is not null`,
+			want: document{"$and": list{
+				document{"a.b": document{"$exists": true}},
+				document{"c": document{"$eq": nil}},
+				document{"d": document{"$ne": nil}},
+			}},
+		},
+		{
+			name:  "Normal case - empty condition",
+			query: ``,
+			want:  document{},
+		},
+		{
+			name:    "Error case - all quantifier",
+			query:   `all(Items, Items.Amount > 1)`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - function",
+			query:   `len(Items) > 1`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - path outside of the collection",
+			query:   `any(Items, Total > 1)`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := structgen.StructGen{LegacyPrecedence: tt.legacyPrecedence}
+			condition, err := gen.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			got, err := ToFilter(condition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToFilter() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "Normal case - implicit and, in name order",
+			filter: `{"status": "paid", "amount": {"$gt": 100, "$lte": 200.5}, "code": "7"}`,
+			want:   `amount > 100 && amount <= 200.5 && code = "7" && status = paid`,
+		},
+		{
+			name:   "Normal case - logical operators",
+			filter: `{"$or": [{"a": 1}, {"$and": [{"b": {"$ne": "x"}}, {"c": {"$in": ["p", "q"]}}]}, {"$nor": [{"d": 1}, {"e": 2}]}]}`,
+			want:   `a = 1 || (b != x && c in (p, q)) || !(d = 1 || e = 2)`,
+		},
+		{
+			name:   "Normal case - not, regex and expr",
+			filter: `{"a": {"$not": {"$nin": [1, "2"]}}, "b": {"$regex": "a\\.b"}, "c": {"$regex": "^re", "$options": ""}, "$expr": {"$gte": ["$debit", "$credit"]}}`,
+			want:   `debit >= $credit && !a not in ("1", "2") && b |= a.b && c |~ ^re`,
+		},
+		{
+			name:   "Normal case - elemMatch",
+			filter: `{"Items": {"$elemMatch": {"Amount": {"$gt": 1000}, "Tags": {"$elemMatch": {"Name": "x"}}}}}`,
+			want:   `any(Items, Items.Amount > 1000 && any(Items.Tags, Items.Tags.Name = x))`,
+		},
+		{
+			name:   "Normal case - empty filter",
+			filter: `{}`,
+			want:   ``,
+		},
+		{
+			name:    "Error case - unknown operator",
+			filter:  `{"a": {"$size": 2}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - regex options",
+			filter:  `{"a": {"$regex": "x", "$options": "i"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - embedded document",
+			filter:  `{"a": {"b": 1}}`,
+			wantErr: true,
+		},
+		{
+			name:   "Normal case - null and exists",
+			filter: `{"a": null, "b": {"$ne": null}, "c": {"$exists": false}, "d": {"$exists": true}}`,
+			want:   `a is null && b is not null && !exists(c) && exists(d)`,
+		},
+		{
+			name:    "Error case - null bound",
+			filter:  `{"a": {"$gt": null}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter document
+			if err := json.Unmarshal([]byte(tt.filter), &filter); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got, err := FromFilter(filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if printed := structgen.Print(got); !tt.wantErr && printed != tt.want {
+				t.Errorf("FromFilter() = %v, want %v", printed, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFilter_RoundTrip(t *testing.T) {
+	queries := []string{
+		`id = 1 && amount >= 10.5 && status != paid && code = "7"`,
+		`(a = 1 || b = 2) && !(c = x && d = y) && e in (1, 2)`,
+		`note |= a.b || name |~ ^re || debit > $credit`,
+		`any(Items, Items.Amount > 1000 && !Items.Void = true)`,
+		`exists(a) && !exists(b) && c is null && d is not null`,
+	}
+	var gen structgen.StructGen
+	for _, query := range queries {
+		condition, err := gen.GenerateCondition(query)
+		if err != nil {
+			t.Fatalf("GenerateCondition(%q) error = %v", query, err)
+		}
+		filter, err := ToFilter(condition)
+		if err != nil {
+			t.Fatalf("ToFilter(%q) error = %v", query, err)
+		}
+		got, err := FromFilter(filter)
+		if err != nil {
+			t.Fatalf("FromFilter(%q) error = %v", query, err)
+		}
+		if !reflect.DeepEqual(got, condition) {
+			t.Errorf("FromFilter(ToFilter(%q)) = %v", query, structgen.Print(got))
+		}
+	}
+}
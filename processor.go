@@ -1,30 +1,47 @@
 package deepvalidator
 
 import (
-	"errors"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
 	structgen "github.com/ahmadrezamusthafa/deep-validator/struct-gen"
 	"github.com/ahmadrezamusthafa/deep-validator/structs"
 	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"reflect"
 )
 
 type Processor interface {
+	SetLegacyPrecedence(value bool) Processor
 	RegisterCondition(astQuery string) Validator
+	RegisterConditionE(astQuery string) (Validator, error)
+	MustRegisterCondition(astQuery string) Validator
 }
 
 type Validator interface {
 	SetRemovePrefix(value bool) Validator
+	SetNameStrategy(strategy namestrategies.NameStrategy) Validator
+	SetNameTag(tagKey string) Validator
+	SetNullPolicy(policy nullpolicies.NullPolicy) Validator
+	SetNumericMode(mode numericmodes.NumericMode) Validator
 	ValidateStruct(data interface{}) (isValid bool, err error)
 	ValidateMultipleStructs(data ...interface{}) (isValid bool, err error)
 	ValidateCondition(inputCondition structs.Condition) (isValid bool, err error)
 	FilterSlice(data interface{}) (result interface{}, err error)
+	Query(data interface{}, base structs.BaseCondition) (result interface{}, err error)
+	Compile(rType reflect.Type) (plan *validators.Plan, err error)
+	Explain(data interface{}) (trace *structs.Trace, err error)
+	ExplainMultipleStructs(data ...interface{}) (trace *structs.Trace, err error)
 	GetCondition() *structs.Condition
 }
 
-type processor struct{}
+type processor struct {
+	legacyPrecedence bool
+}
 
 type validator struct {
 	attributeNames     map[string]interface{}
 	conditionValidator validators.ConditionValidator
+	err                error
 }
 
 func NewProcessor() Processor {
@@ -54,13 +71,127 @@ func GenerateCondition(astQuery string) (structs.Condition, error) {
 	return gen.GenerateCondition(astQuery)
 }
 
-func (p *processor) RegisterCondition(astQuery string) Validator {
+/*
+GenerateBaseCondition
+-----------------------------------------------------------------------
+generates the base condition used by Query from a query followed by
+optional clauses:
+
+	status=active && amount>100 | sort createdAt desc, id asc | limit 20 offset 40 | fields id,amount | distinct
+
+Param:
+@astQuery is abstract syntax tree query
+*/
+func GenerateBaseCondition(astQuery string) (structs.BaseCondition, error) {
 	var gen structgen.StructGen
+	return gen.GenerateBaseCondition(astQuery)
+}
+
+/*
+Print
+-----------------------------------------------------------------------
+formats a condition as canonical query text, quoting the names and
+values that need it and keeping the parentheses of every group, so
+GenerateCondition(Print(condition)) gives back the same condition.
+
+Param:
+@condition is the condition generated by GenerateCondition
+*/
+func Print(condition structs.Condition) string {
+	return structgen.Print(condition)
+}
+
+/*
+PrintPretty
+-----------------------------------------------------------------------
+formats a condition like Print, one term per line with the content of
+groups and quantifiers indented, for rules too long to read on one line.
+
+Param:
+@condition is the condition generated by GenerateCondition
+*/
+func PrintPretty(condition structs.Condition) string {
+	return structgen.PrintPretty(condition)
+}
+
+/*
+Compile
+-----------------------------------------------------------------------
+compiles a condition into a plan for one struct or map type. The plan
+resolves field indexes, parses the condition values and compiles the
+regexes once, and is safe to share across goroutines.
+
+Param:
+@condition is the condition generated by GenerateCondition
+@rType is the type of the data the plan validates, e.g. reflect.TypeOf(Event{})
+*/
+func Compile(condition structs.Condition, rType reflect.Type) (*validators.Plan, error) {
+	return validators.Compile(&condition, rType)
+}
+
+/*
+Query
+-----------------------------------------------------------------------
+runs an in-memory query over the data slice:
+  - keeps the items matching the conditions of base
+  - orders them by base.Footer.Sort, in the order of Footer.SortKeys
+  - projects base.Fields, dropping duplicate rows when base.Distinct
+  - returns the page given by Footer.Page and Footer.Limit
+
+The result is a slice of the type of data, or a []map[string]interface{}
+keyed by field name when base has fields. Attribute names are matched
+against the Go field names, use Validator.Query for other settings.
+*/
+func Query(data interface{}, base structs.BaseCondition) (result interface{}, err error) {
+	return validators.NewConditionValidator(&structs.Condition{}).Query(data, base)
+}
+
+/*
+SetLegacyPrecedence
+-----------------------------------------------------------------------
+parses the queries registered afterwards left to right, giving `&&` and
+`||` the same precedence, for stored queries written for that order:
+  - false: `a || b && c` is `a || (b && c)` (default)
+  - true: `a || b && c` is `(a || b) && c`
+*/
+func (p *processor) SetLegacyPrecedence(value bool) Processor {
+	p.legacyPrecedence = value
+	return p
+}
+
+/*
+RegisterCondition
+-----------------------------------------------------------------------
+registers the query and returns its validator. When the query can't be
+parsed every validation returns the *structgen.ParseError, use
+RegisterConditionE or MustRegisterCondition to get it at load time.
+*/
+func (p *processor) RegisterCondition(astQuery string) Validator {
+	v, err := p.RegisterConditionE(astQuery)
+	if err != nil {
+		return &validator{
+			conditionValidator: validators.NewConditionValidator(nil),
+			err:                err,
+		}
+	}
+	return v
+}
+
+func (p *processor) RegisterConditionE(astQuery string) (Validator, error) {
+	gen := structgen.StructGen{LegacyPrecedence: p.legacyPrecedence}
 	condition, err := gen.GenerateCondition(astQuery)
 	if err != nil {
-		return newValidator(nil, nil)
+		return nil, err
+	}
+	return newValidator(gen.AttributeNames, validators.NewConditionValidator(&condition)), nil
+}
+
+func (p *processor) MustRegisterCondition(astQuery string) Validator {
+	v, err := p.RegisterConditionE(astQuery)
+	if err != nil {
+		panic(err)
 	}
-	return newValidator(gen.AttributeNames, validators.NewConditionValidator(&condition))
+	return v
 }
 
 func (v *validator) SetRemovePrefix(value bool) Validator {
@@ -71,34 +202,183 @@ func (v *validator) SetRemovePrefix(value bool) Validator {
 	return v
 }
 
-func (v *validator) ValidateStruct(data interface{}) (isValid bool, err error) {
+/*
+SetNameStrategy
+-----------------------------------------------------------------------
+sets which name of a struct field the attribute names of the condition are
+matched against in ValidateStruct, ValidateMultipleStructs and FilterSlice:
+  - namestrategies.FieldName matches the Go field name (default)
+  - namestrategies.JSONTag matches the json tag name
+  - namestrategies.SnakeCase matches the snake_case Go field name
+*/
+func (v *validator) SetNameStrategy(strategy namestrategies.NameStrategy) Validator {
+	if v.conditionValidator.GetCondition() == nil {
+		return v
+	}
+	v.conditionValidator.SetNameStrategy(strategy)
+	return v
+}
+
+/*
+SetNameTag
+-----------------------------------------------------------------------
+matches the attribute names of the condition against a custom struct tag,
+e.g. SetNameTag("dv") for `dv:"member_id"`. Fields without the tag keep
+their Go field name.
+*/
+func (v *validator) SetNameTag(tagKey string) Validator {
+	if v.conditionValidator.GetCondition() == nil {
+		return v
+	}
+	v.conditionValidator.SetNameTag(tagKey)
+	return v
+}
+
+/*
+SetNullPolicy
+-----------------------------------------------------------------------
+sets what a comparison gives when its field is nil, e.g. a nil *string,
+or its attribute path can't be resolved, e.g. a missing map key:
+  - nullpolicies.False: the comparison is false, `!` makes it true, so
+    `SenderType != bca` is false and `!(SenderType = bca)` true (default)
+  - nullpolicies.ZeroValue: the zero value of the field type is compared,
+    so `SenderType != bca` is true
+  - nullpolicies.Unknown: the comparison is unknown like NULL in SQL, `!`
+    keeps it unknown and an unknown condition doesn't match
+  - nullpolicies.Error: the validation returns an *UnknownAttributeError
+
+exists(path), path is null and path is not null test for nil and missing
+values explicitly and give the same result under every policy.
+*/
+func (v *validator) SetNullPolicy(policy nullpolicies.NullPolicy) Validator {
 	if v.conditionValidator.GetCondition() == nil {
-		return false, errors.New("condition is nil")
+		return v
+	}
+	v.conditionValidator.SetNullPolicy(policy)
+	return v
+}
+
+/*
+SetNumericMode
+-----------------------------------------------------------------------
+sets how numbers are compared:
+  - numericmodes.Float: a condition value is parsed as the type of its
+    field, integers compare exactly and floats as float64 (default)
+  - numericmodes.Decimal: every number compares exactly as a math/big
+    decimal, so a float64 field holding 0.1 equals `0.1`, int64 and
+    uint64 fields compare beyond 2^53, and a string field holding a
+    decimal, e.g. "1500.25", compares as that number
+
+big.Int and big.Rat fields, and json.Number values that aren't
+integers, compare exactly in both modes. A condition value that isn't
+a number fails the comparison of a number with a *TypeMismatchError,
+it never compares as 0. ValidateCondition, where the values of both
+conditions are text, keeps reading such a value as 0 under Float and
+fails with a *TypeMismatchError under Decimal.
+*/
+func (v *validator) SetNumericMode(mode numericmodes.NumericMode) Validator {
+	if v.conditionValidator.GetCondition() == nil {
+		return v
+	}
+	v.conditionValidator.SetNumericMode(mode)
+	return v
+}
+
+func (v *validator) ValidateStruct(data interface{}) (isValid bool, err error) {
+	if err := v.validate(); err != nil {
+		return false, err
 	}
 	return v.conditionValidator.Validate(data)
 }
 
 func (v *validator) ValidateMultipleStructs(data ...interface{}) (isValid bool, err error) {
-	if v.conditionValidator.GetCondition() == nil {
-		return false, errors.New("condition is nil")
+	if err := v.validate(); err != nil {
+		return false, err
 	}
 	return v.conditionValidator.ValidateObjects(v.attributeNames, data...)
 }
 
 func (v *validator) ValidateCondition(inputCondition structs.Condition) (isValid bool, err error) {
-	if v.conditionValidator.GetCondition() == nil {
-		return false, errors.New("condition is nil")
+	if err := v.validate(); err != nil {
+		return false, err
 	}
 	return v.conditionValidator.ValidateCondition(inputCondition)
 }
 
 func (v *validator) FilterSlice(data interface{}) (result interface{}, err error) {
-	if v.conditionValidator.GetCondition() == nil {
-		return false, errors.New("condition is nil")
+	if err := v.validate(); err != nil {
+		return nil, err
 	}
 	return v.conditionValidator.FilterSlice(data)
 }
 
+/*
+Query
+-----------------------------------------------------------------------
+is the Query of the package filtering with the condition of the
+validator as well, with its SetRemovePrefix, SetNameStrategy,
+SetNameTag, SetNullPolicy and SetNumericMode settings.
+*/
+func (v *validator) Query(data interface{}, base structs.BaseCondition) (result interface{}, err error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v.conditionValidator.Query(data, base)
+}
+
+/*
+Compile
+-----------------------------------------------------------------------
+compiles the condition into a plan for data of rType, keeping the
+SetRemovePrefix, SetNameStrategy, SetNameTag, SetNullPolicy and
+SetNumericMode settings of the validator. plan.Validate gives the
+result of ValidateStruct.
+*/
+func (v *validator) Compile(rType reflect.Type) (plan *validators.Plan, err error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v.conditionValidator.Compile(rType)
+}
+
+/*
+Explain
+-----------------------------------------------------------------------
+validates data like ValidateStruct and returns a trace mirroring the
+condition tree. Every node holds its result and whether it was skipped,
+every comparison the resolved field value and the coerced condition
+value. The trace renders as JSON with json.Marshal and as an indented
+text tree with String.
+*/
+func (v *validator) Explain(data interface{}) (trace *structs.Trace, err error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v.conditionValidator.Explain(data)
+}
+
+/*
+ExplainMultipleStructs
+-----------------------------------------------------------------------
+is the Explain of ValidateMultipleStructs.
+*/
+func (v *validator) ExplainMultipleStructs(data ...interface{}) (trace *structs.Trace, err error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+	return v.conditionValidator.ExplainObjects(v.attributeNames, data...)
+}
+
+func (v *validator) validate() error {
+	if v.err != nil {
+		return v.err
+	}
+	if v.conditionValidator.GetCondition() == nil {
+		return &validators.InvalidConditionError{Reason: "condition is nil"}
+	}
+	return nil
+}
+
 func (v *validator) GetCondition() *structs.Condition {
 	if v.conditionValidator.GetCondition() == nil {
 		return nil
@@ -1,6 +1,7 @@
 package deepvalidator
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -54,6 +55,26 @@ func BenchmarkValidate(b *testing.B) {
 	}
 }
 
+// BENCHMARK Plan.Validate
+// Same query and object as BenchmarkValidate, compiled once.
+func BenchmarkValidatePlan(b *testing.B) {
+	object := struct {
+		ID       string `json:"id"`
+		MemberID string `json:"member_id"`
+		Division string `json:"division"`
+	}{
+		ID:       "1",
+		MemberID: "2",
+		Division: "finance",
+	}
+
+	query := "(id=1 && (member_id=12||member_id=2))  &&   (division=engineering || division=finance)"
+	plan, _ := NewProcessor().RegisterCondition(query).Compile(reflect.TypeOf(object))
+	for n := 0; n < b.N; n++ {
+		_, _ = plan.Validate(object)
+	}
+}
+
 // BENCHMARK ValidateMultipleStructs
 // Improvement history:
 // ------------------------------------
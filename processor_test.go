@@ -1,10 +1,20 @@
 package deepvalidator
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	structgen "github.com/ahmadrezamusthafa/deep-validator/struct-gen"
 	structs2 "github.com/ahmadrezamusthafa/deep-validator/structs"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -46,7 +56,7 @@ func TestGenerateCondition(t *testing.T) {
                       ) && user_id = 43
 `,
 			},
-			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}`,
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -267,6 +277,81 @@ func TestCondition_ValidateStruct(t *testing.T) {
 			wantIsValid: true,
 			wantErr:     false,
 		},
+		{
+			name: "Normal case - struct validation - negated group",
+			args: args{
+				query: `ID=1 && !(Division=engineering || Division=finance)`,
+				object: struct {
+					ID       int
+					Division string
+				}{
+					ID:       1,
+					Division: "people",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - negated group not match",
+			args: args{
+				query: `ID=1 && !(Division=engineering || Division=finance)`,
+				object: struct {
+					ID       int
+					Division string
+				}{
+					ID:       1,
+					Division: "finance",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - negated comparison",
+			args: args{
+				query: `!ID=2 && !!Division=finance`,
+				object: struct {
+					ID       int
+					Division string
+				}{
+					ID:       1,
+					Division: "finance",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - in",
+			args: args{
+				query: `ID in (1, 2, 3) && Division in ("engineering", "finance")`,
+				object: struct {
+					ID       int
+					Division string
+				}{
+					ID:       2,
+					Division: "finance",
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
+		{
+			name: "Normal case - struct validation - not in",
+			args: args{
+				query: `ID not in (1, 2, 3) || Division not in (engineering, finance)`,
+				object: struct {
+					ID       int
+					Division string
+				}{
+					ID:       2,
+					Division: "finance",
+				},
+			},
+			wantIsValid: false,
+			wantErr:     false,
+		},
 		{
 			name: "Error case",
 			args: args{
@@ -447,6 +532,20 @@ func TestCondition_ValidateMultipleStructs(t *testing.T) {
 			wantIsValid: false,
 			wantErr:     false,
 		},
+		{
+			name: "Normal case - multi struct validation - in",
+			args: args{
+				query: `Type in (ABC, DEF) && Name not in (Foo, Bar)`,
+				data: []interface{}{
+					thirdData,
+					secondStruct{
+						Name: "Test",
+					},
+				},
+			},
+			wantIsValid: true,
+			wantErr:     false,
+		},
 		{
 			name: "Normal case - multi struct validation - all attributes exist",
 			args: args{
@@ -728,6 +827,48 @@ func TestCondition_ValidateCondition(t *testing.T) {
 			wantIsValid:    true,
 			wantErr:        false,
 		},
+		{
+			name:           "Normal case - negated reference group",
+			referenceQuery: "id=1 && !(segment=trial || segment=free)",
+			input:          "id=1 && segment=premium",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - negated reference group",
+			referenceQuery: "id=1 && !(segment=trial || segment=free)",
+			input:          "id=1 && segment=free",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - negated input",
+			referenceQuery: "id=1 && segment=trial",
+			input:          "id=1 && !segment=trial",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - negated input",
+			referenceQuery: "id=1 && segment=free",
+			input:          "id=1 && !segment=trial",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - in",
+			referenceQuery: "id=1 && segment in (trial, free)",
+			input:          "id=1 && segment=FREE",
+			wantIsValid:    true,
+			wantErr:        false,
+		},
+		{
+			name:           "Normal case - not in",
+			referenceQuery: "id=1 && segment not in (trial, free)",
+			input:          "id=1 && segment=free",
+			wantIsValid:    false,
+			wantErr:        false,
+		},
 		{
 			name:           "Normal case - greater than operator - integer",
 			referenceQuery: "(id=1 || id=2) && member_id>100",
@@ -749,6 +890,13 @@ func TestCondition_ValidateCondition(t *testing.T) {
 			wantIsValid:    true,
 			wantErr:        false,
 		},
+		{
+			name:           "Normal case - greater than equal operator - RFC 3339 datetime",
+			referenceQuery: `(id=1 || id=2) && create_date>=2020-02-02T12:12:12Z`,
+			input:          `id=1 && create_date=2020-02-02T12:12:12Z`,
+			wantIsValid:    true,
+			wantErr:        false,
+		},
 		{
 			name:           "Normal case - greater than operator - float",
 			referenceQuery: "(id=1 || id=2) && price>1200.50 && (segment=hijaber||segment=girl||segment=cantik) && poin>100",
@@ -1013,6 +1161,59 @@ func TestCondition_FilterSlice(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Normal case - negated group",
+			args: args{
+				query:   "ID<=3 && !(Division=people || Division=business)",
+				objects: testData,
+			},
+			wantResults: []Account{
+				{
+					ID:        2,
+					MemberID:  22,
+					Division:  "finance",
+					Score:     fInt(40),
+					Point:     fInt64(1000),
+					Wallet:    fFloat(1000),
+					Money:     fFloat64(50000),
+					JoinDate:  time.Date(2014, 1, 9, 0, 0, 0, 0, time.UTC),
+					LeaveDate: fTime(time.Date(2015, 12, 9, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Normal case - in over pointer and time fields",
+			args: args{
+				query:   `Point in (1000, 3000) && JoinDate not in ("2015-07-09T00:00:00Z") && Money in (50000, 1500000.0)`,
+				objects: testData,
+			},
+			wantResults: []Account{
+				{
+					ID:        2,
+					MemberID:  22,
+					Division:  "finance",
+					Score:     fInt(40),
+					Point:     fInt64(1000),
+					Wallet:    fFloat(1000),
+					Money:     fFloat64(50000),
+					JoinDate:  time.Date(2014, 1, 9, 0, 0, 0, 0, time.UTC),
+					LeaveDate: fTime(time.Date(2015, 12, 9, 0, 0, 0, 0, time.UTC)),
+				},
+				{
+					ID:        5,
+					MemberID:  25,
+					Division:  "engineering",
+					Score:     fInt(100),
+					Point:     fInt64(3000),
+					Wallet:    fFloat(100),
+					Money:     fFloat64(1500000),
+					JoinDate:  time.Date(2015, 10, 9, 0, 0, 0, 0, time.UTC),
+					LeaveDate: nil,
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Normal case - empty",
 			args: args{
@@ -1055,3 +1256,1745 @@ func TestCondition_FilterSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessor_RegisterConditionE(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{
+			name:  "Normal case",
+			query: `ID=1 && (Division=engineering || Division=finance)`,
+		},
+		{
+			name:    "Error case - unbalanced parenthesis",
+			query:   `ID=1 && (Division=engineering || Division=finance`,
+			wantErr: true,
+		},
+		{
+			name:    "Error case - dangling logical operator",
+			query:   `ID=1 ||`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProcessor().RegisterConditionE(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Processor.RegisterConditionE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if _, ok := err.(*structgen.ParseError); !ok {
+				t.Errorf("Processor.RegisterConditionE() error = %T, want *structgen.ParseError", err)
+			}
+			_, validateErr := NewProcessor().RegisterCondition(tt.query).ValidateStruct(struct{ ID int }{ID: 1})
+			if validateErr == nil || validateErr.Error() != err.Error() {
+				t.Errorf("Validator.ValidateStruct() error = %v, want %v", validateErr, err)
+			}
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Processor.MustRegisterCondition() did not panic")
+				}
+			}()
+			NewProcessor().MustRegisterCondition(tt.query)
+		})
+	}
+}
+
+func TestValidator_SetNameStrategy(t *testing.T) {
+	type Member struct {
+		MemberID int    `json:"member_id" dv:"member"`
+		Division string `json:"division" dv:"div"`
+	}
+	type Event struct {
+		EventSource string `json:"eventSource"`
+	}
+
+	members := []Member{
+		{MemberID: 1, Division: "engineering"},
+		{MemberID: 2, Division: "finance"},
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		setStrategy  func(v Validator) Validator
+		wantIsValid  bool
+		wantMultiple bool
+		wantErr      bool
+		wantFiltered []Member
+	}{
+		{
+			name:  "Normal case - go field name",
+			query: `MemberID=2 && Division=finance`,
+			setStrategy: func(v Validator) Validator {
+				return v
+			},
+			wantIsValid:  true,
+			wantMultiple: true,
+			wantFiltered: []Member{members[1]},
+		},
+		{
+			name:  "Normal case - go field name doesn't match json tag",
+			query: `member_id=2 && division=finance`,
+			setStrategy: func(v Validator) Validator {
+				return v
+			},
+			wantIsValid:  false,
+			wantMultiple: false,
+			wantErr:      true,
+			wantFiltered: []Member{},
+		},
+		{
+			name:  "Normal case - json tag",
+			query: `member_id=2 && division=finance`,
+			setStrategy: func(v Validator) Validator {
+				return v.SetNameStrategy(namestrategies.JSONTag)
+			},
+			wantIsValid:  true,
+			wantMultiple: true,
+			wantFiltered: []Member{members[1]},
+		},
+		{
+			name:  "Normal case - snake case",
+			query: `member_id=2 && division=finance`,
+			setStrategy: func(v Validator) Validator {
+				return v.SetNameStrategy(namestrategies.SnakeCase)
+			},
+			wantIsValid:  true,
+			wantMultiple: true,
+			wantFiltered: []Member{members[1]},
+		},
+		{
+			name:  "Normal case - custom tag",
+			query: `member=2 && div=finance`,
+			setStrategy: func(v Validator) Validator {
+				return v.SetNameTag("dv")
+			},
+			wantIsValid:  true,
+			wantMultiple: true,
+			wantFiltered: []Member{members[1]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := tt.setStrategy(NewProcessor().RegisterCondition(tt.query))
+			gotIsValid, err := proc.ValidateStruct(members[1])
+			if err != nil {
+				t.Fatalf("Validator.ValidateStruct() error = %v", err)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateStruct() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+			gotMultiple, err := proc.ValidateMultipleStructs(members[1], Event{EventSource: "api"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validator.ValidateMultipleStructs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotMultiple != tt.wantMultiple {
+				t.Errorf("Validator.ValidateMultipleStructs() = %v, want %v", gotMultiple, tt.wantMultiple)
+			}
+			gotFiltered, err := proc.FilterSlice(members)
+			if err != nil {
+				t.Fatalf("Validator.FilterSlice() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotFiltered, tt.wantFiltered) {
+				t.Errorf("Validator.FilterSlice() = %v, want %v", gotFiltered, tt.wantFiltered)
+			}
+		})
+	}
+}
+
+func TestValidator_NestedPath(t *testing.T) {
+	type Sender struct {
+		Type string `json:"type"`
+	}
+	type Audit struct {
+		CreatedBy string
+	}
+	type Payload struct {
+		Status    string                 `json:"status"`
+		Sender    *Sender                `json:"sender"`
+		ExtraInfo map[string]interface{} `json:"extraInfo"`
+		Meta      interface{}            `json:"meta"`
+	}
+	type Event struct {
+		Audit
+		Status  string   `json:"status"`
+		Payload Payload  `json:"payload"`
+		Retry   *Payload `json:"retry"`
+	}
+
+	event := Event{
+		Audit:  Audit{CreatedBy: "system"},
+		Status: "published",
+		Payload: Payload{
+			Status: "paid",
+			Sender: &Sender{Type: "bank"},
+			ExtraInfo: map[string]interface{}{
+				"channel": "mobile",
+				"device":  map[string]interface{}{"os": "android"},
+				"tags":    []interface{}{"promo", "new"},
+			},
+			Meta: Sender{Type: "meta"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		jsonTag     bool
+		data        interface{}
+		wantIsValid bool
+	}{
+		{
+			name:        "Normal case - top level field hides nested field",
+			query:       `Status=published && Payload.Status=paid`,
+			data:        event,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - pointer, map and interface",
+			query:       `Payload.Sender.Type=bank && Payload.ExtraInfo.channel=mobile && Payload.ExtraInfo.device.os=android && Payload.Meta.Type=meta`,
+			data:        &event,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - slice index and promoted field",
+			query:       `Payload.ExtraInfo.tags.1=new && CreatedBy=system && Audit.CreatedBy=system`,
+			data:        event,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - json tag path",
+			query:       `payload.sender.type=bank && payload.extraInfo.channel=mobile`,
+			jsonTag:     true,
+			data:        event,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - nil intermediate pointer never matches",
+			query:       `Retry.Status=paid || Retry.Status!=paid`,
+			data:        event,
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - missing map key never matches",
+			query:       `Payload.ExtraInfo.unknown=mobile || Payload.ExtraInfo.unknown!=mobile`,
+			data:        event,
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - map data",
+			query:       `event.payload.sender.type=bank`,
+			data:        map[string]interface{}{"event": map[string]interface{}{"payload": map[string]interface{}{"sender": map[string]interface{}{"type": "bank"}}}},
+			wantIsValid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := NewProcessor().MustRegisterCondition(tt.query)
+			if tt.jsonTag {
+				proc.SetNameStrategy(namestrategies.JSONTag)
+			}
+			gotIsValid, err := proc.ValidateStruct(tt.data)
+			if err != nil {
+				t.Fatalf("Validator.ValidateStruct() error = %v", err)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateStruct() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestValidator_NestedPathMultipleStructs(t *testing.T) {
+	type StatementPayload struct {
+		PartnerId string `json:"partnerId"`
+		Status    string `json:"status"`
+	}
+	type PartnerStatementUpdatedEvent struct {
+		Status  string           `json:"status"`
+		Payload StatementPayload `json:"payload"`
+	}
+	type TransactionPayload struct {
+		Status string `json:"status"`
+	}
+	type TransactionUpdatedEvent struct {
+		Payload TransactionPayload `json:"payload"`
+	}
+
+	data := []interface{}{
+		PartnerStatementUpdatedEvent{
+			Status:  "published",
+			Payload: StatementPayload{PartnerId: "bca", Status: "matched"},
+		},
+		TransactionUpdatedEvent{
+			Payload: TransactionPayload{Status: "completed"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		jsonTag      bool
+		removePrefix bool
+		wantIsValid  bool
+	}{
+		{
+			name:        "Normal case - bare names",
+			query:       `PartnerId=bca && Status=published`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - type name prefix",
+			query:       `PartnerStatementUpdatedEvent.Payload.Status=matched && TransactionUpdatedEvent.Payload.Status=completed`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - type name prefix with json tags",
+			query:       `PartnerStatementUpdatedEvent.payload.partnerId=bca && TransactionUpdatedEvent.payload.status=completed`,
+			jsonTag:     true,
+			wantIsValid: true,
+		},
+		{
+			name:         "Normal case - remove prefix",
+			query:        `Event.PartnerId=bca || PartnerId=bni`,
+			removePrefix: true,
+			wantIsValid:  true,
+		},
+		{
+			name:        "Normal case - prefix is kept by default",
+			query:       `Event.PartnerId=bca || PartnerId=bni`,
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := NewProcessor().MustRegisterCondition(tt.query).SetRemovePrefix(tt.removePrefix)
+			if tt.jsonTag {
+				proc.SetNameStrategy(namestrategies.JSONTag)
+			}
+			gotIsValid, err := proc.ValidateMultipleStructs(data)
+			if err != nil {
+				t.Fatalf("Validator.ValidateMultipleStructs() error = %v", err)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateMultipleStructs() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+type cyclicNode struct {
+	Name   string
+	Parent *cyclicNode
+}
+
+func TestValidator_CyclicMultipleStructs(t *testing.T) {
+	node := &cyclicNode{Name: "root"}
+	node.Parent = node
+	validator := NewProcessor().RegisterCondition(`Name = root && Parent.Parent.Name = root`)
+	isValid, err := validator.ValidateMultipleStructs(node)
+	if err != nil || !isValid {
+		t.Errorf("Validator.ValidateMultipleStructs() = %v, %v, want true", isValid, err)
+	}
+}
+
+func TestValidator_Quantifiers(t *testing.T) {
+	type LineItem struct {
+		Sku    string   `json:"sku"`
+		Amount float64  `json:"amount"`
+		Codes  []string `json:"codes"`
+	}
+	type Order struct {
+		Status string      `json:"status"`
+		Items  []LineItem  `json:"items"`
+		Refs   []*LineItem `json:"refs"`
+		Tags   []string    `json:"tags"`
+		Extra  map[string]interface{}
+	}
+
+	order := Order{
+		Status: "paid",
+		Items: []LineItem{
+			{Sku: "A-1", Amount: 500, Codes: []string{"x"}},
+			{Sku: "B-2", Amount: 1500, Codes: []string{"x", "y", "z"}},
+		},
+		Refs: []*LineItem{nil, {Sku: "C-3", Amount: 10}},
+		Tags: []string{"promo", "new"},
+		Extra: map[string]interface{}{
+			"labels": []interface{}{"vip", "priority"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		data        interface{}
+		wantIsValid bool
+		wantErr     bool
+	}{
+		{
+			name:        "Normal case - any",
+			query:       `any(Items, Items.Amount > 1000)`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - any without match",
+			query:       `any(Items, Items.Amount > 5000)`,
+			data:        &order,
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - all over strings",
+			query:       `all(Tags, Tags != "test") && Status=paid`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - all with sequence",
+			query:       `all(Items, Items.Amount > 100 && Items.Sku |= "-")`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - none",
+			query:       `none(Tags, Tags = test || Tags = draft)`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - nested quantifier and len",
+			query:       `any(Items, len(Items.Codes) >= 3 && any(Items.Codes, Items.Codes = z))`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - nil element never matches",
+			query:       `any(Refs, Refs.Sku = C-3) && !all(Refs, Refs.Amount >= 0)`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - slice inside map",
+			query:       `any(Extra.labels, Extra.labels = vip) && len(Extra.labels) = 2`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - len",
+			query:       `len(Items) >= 3 || len(Tags) = 2 && len(Status) = 4`,
+			data:        order,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - empty collection",
+			query:       `all(Items, Items.Amount > 0) && none(Items, Items.Amount > 0) && !any(Items, Items.Amount > 0)`,
+			data:        Order{},
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - collection that is not a slice",
+			query:       `any(Status, Status = paid) || len(Unknown) = 0`,
+			data:        order,
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIsValid, err := NewProcessor().MustRegisterCondition(tt.query).ValidateStruct(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validator.ValidateStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateStruct() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+
+	multipleTests := []struct {
+		name        string
+		query       string
+		wantIsValid bool
+	}{
+		{
+			name:        "Normal case - bare collection name",
+			query:       `any(Items, Items.Amount > 1000) && len(Tags) = 2`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - type name prefix",
+			query:       `all(Order.Items, Order.Items.Amount >= 500) && none(Order.Tags, Order.Tags = test)`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - no match",
+			query:       `any(Items, Items.Sku = Z-9)`,
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range multipleTests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIsValid, err := NewProcessor().MustRegisterCondition(tt.query).ValidateMultipleStructs([]interface{}{order})
+			if err != nil {
+				t.Fatalf("Validator.ValidateMultipleStructs() error = %v", err)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateMultipleStructs() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+}
+
+func TestValidator_FieldReference(t *testing.T) {
+	type Payload struct {
+		Debit       float64    `json:"debit"`
+		Credit      int64      `json:"credit"`
+		Status      string     `json:"status"`
+		ConfirmedAt *time.Time `json:"confirmedAt"`
+		CompletedAt *time.Time `json:"completedAt"`
+		RetryAt     *time.Time `json:"retryAt"`
+	}
+	type Event struct {
+		TotalAmount float64 `json:"totalAmount"`
+		Limit       int     `json:"limit"`
+		Status      string  `json:"status"`
+		Payload     Payload `json:"payload"`
+	}
+
+	confirmedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	completedAt := confirmedAt.Add(time.Minute)
+	event := Event{
+		TotalAmount: 50000,
+		Limit:       40000,
+		Status:      "paid",
+		Payload: Payload{
+			Debit:       50000,
+			Credit:      40000,
+			Status:      "PAID",
+			ConfirmedAt: &confirmedAt,
+			CompletedAt: &completedAt,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		jsonTag     bool
+		wantIsValid bool
+	}{
+		{
+			name:        "Normal case - numeric fields",
+			query:       `Payload.Debit = $TotalAmount && Payload.Credit = $Limit && TotalAmount > $Payload.Credit`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - time fields",
+			query:       `Payload.CompletedAt >= $Payload.ConfirmedAt && Payload.ConfirmedAt < $Payload.CompletedAt`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - string fields",
+			query:       `Status != $Payload.Status && Payload.Status |= "P"`,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - json tags",
+			query:       `payload.debit = $totalAmount`,
+			jsonTag:     true,
+			wantIsValid: true,
+		},
+		{
+			name:        "Normal case - quoted value is a literal",
+			query:       `Status = "$Payload.Status"`,
+			wantIsValid: false,
+		},
+		{
+			name:        "Normal case - nil or missing reference never matches",
+			query:       `Payload.CompletedAt >= $Payload.RetryAt || Payload.CompletedAt != $Payload.RetryAt || Status = $Unknown`,
+			wantIsValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proc := NewProcessor().MustRegisterCondition(tt.query)
+			if tt.jsonTag {
+				proc.SetNameStrategy(namestrategies.JSONTag)
+			}
+			gotIsValid, err := proc.ValidateStruct(event)
+			if err != nil {
+				t.Fatalf("Validator.ValidateStruct() error = %v", err)
+			}
+			if gotIsValid != tt.wantIsValid {
+				t.Errorf("Validator.ValidateStruct() = %v, want %v", gotIsValid, tt.wantIsValid)
+			}
+		})
+	}
+
+	type PartnerStatementUpdatedEvent struct {
+		Payload Payload
+	}
+	type TransactionUpdatedEvent struct {
+		TotalAmount float64
+	}
+	data := []interface{}{
+		PartnerStatementUpdatedEvent{Payload: Payload{Debit: 50000}},
+		TransactionUpdatedEvent{TotalAmount: 50000},
+	}
+	for _, query := range []string{
+		`PartnerStatementUpdatedEvent.Payload.Debit = $TransactionUpdatedEvent.TotalAmount`,
+		`Debit = $TotalAmount`,
+	} {
+		gotIsValid, err := NewProcessor().MustRegisterCondition(query).ValidateMultipleStructs(data)
+		if err != nil || !gotIsValid {
+			t.Errorf("Validator.ValidateMultipleStructs(%q) = %v, %v, want true", query, gotIsValid, err)
+		}
+	}
+
+	proc := NewProcessor().MustRegisterCondition(`debit = $total_amount`)
+	for input, want := range map[string]bool{
+		`debit = 5 && total_amount = 5`: true,
+		`debit = 5 && total_amount = 6`: false,
+		`debit = 5`:                     false,
+	} {
+		condition, _ := new(structgen.StructGen).GenerateCondition(input)
+		gotIsValid, err := proc.ValidateCondition(condition)
+		if err != nil || gotIsValid != want {
+			t.Errorf("Validator.ValidateCondition(%q) = %v, %v, want %v", input, gotIsValid, err, want)
+		}
+	}
+}
+
+func TestValidator_Explain(t *testing.T) {
+	type Item struct {
+		Amount float64
+	}
+	type Order struct {
+		Status string
+		Total  int
+		Max    *int
+		Items  []Item
+		Ref    *Item
+	}
+	max := 20
+	order := Order{Status: "paid", Total: 50, Max: &max, Items: []Item{{Amount: 5}, {Amount: 20}}}
+
+	tests := []struct {
+		name     string
+		query    string
+		data     interface{}
+		wantText string
+		wantJSON string
+	}{
+		{
+			name:  "Normal case - comparisons and groups",
+			query: `Status = paid && (Total > 100 || !Total <= $Max) && Ref.Amount = 1`,
+			data:  order,
+			wantText: `false   group
+  true    Status = paid [field: "paid", value: "paid"]
+  true    AND group
+    false   Total > 100 [field: 50, value: 100]
+    true    OR NOT Total <= $Max [field: 50, value: 20]
+  false   AND Ref.Amount = 1 [missing]
+`,
+			wantJSON: `{"result":false,"conditions":[{"attribute":{"name":"Status","operator":"=","value":"paid","fieldValue":"paid","conditionValue":"paid"},"result":true},{"operator":"AND","result":true,"conditions":[{"attribute":{"name":"Total","operator":"\u003e","value":"100","fieldValue":50,"conditionValue":100},"result":false},{"operator":"OR","negate":true,"attribute":{"name":"Total","operator":"\u003c=","reference":"Max","fieldValue":50,"conditionValue":20},"result":true}]},{"operator":"AND","attribute":{"name":"Ref.Amount","operator":"=","value":"1","fieldValue":null,"conditionValue":null,"missing":true},"result":false}]}`,
+		},
+		{
+			name:  "Normal case - quantifier and list",
+			query: `any(Items, Items.Amount > 10) && Status not in (void, "on hold")`,
+			data:  &order,
+			wantText: `true    group
+  true    any(Items)
+    [0] false
+      false   Items.Amount > 10 [field: 5, value: 10]
+    [1] true
+      true    Items.Amount > 10 [field: 20, value: 10]
+  true    AND Status not in (void, on hold) [field: "paid", value: [void on hold]]
+`,
+			wantJSON: `{"result":true,"conditions":[{"quantifier":"any","collection":"Items","result":true,"elements":[{"index":0,"result":false,"conditions":[{"attribute":{"name":"Items.Amount","operator":"\u003e","value":"10","fieldValue":5,"conditionValue":10},"result":false}]},{"index":1,"result":true,"conditions":[{"attribute":{"name":"Items.Amount","operator":"\u003e","value":"10","fieldValue":20,"conditionValue":10},"result":true}]}]},{"operator":"AND","attribute":{"name":"Status","operator":"not in","values":["void","on hold"],"fieldValue":"paid","conditionValue":["void","on hold"]},"result":true}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query)
+			trace, err := validator.Explain(tt.data)
+			if err != nil {
+				t.Fatalf("Validator.Explain() error = %v", err)
+			}
+			if got := trace.String(); got != tt.wantText {
+				t.Errorf("Validator.Explain() text = \n%v, want \n%v", got, tt.wantText)
+			}
+			byteBuf, _ := json.Marshal(trace)
+			if string(byteBuf) != tt.wantJSON {
+				t.Errorf("Validator.Explain() json = %v, want %v", string(byteBuf), tt.wantJSON)
+			}
+			isValid, _ := validator.ValidateStruct(tt.data)
+			if isValid != trace.Result {
+				t.Errorf("Validator.Explain() result = %v, want %v", trace.Result, isValid)
+			}
+		})
+	}
+
+	trace, err := NewProcessor().MustRegisterCondition(`Status = paid && Name = Test`).
+		ExplainMultipleStructs(order, struct{ Name string }{Name: "Test"})
+	if err != nil || !trace.Result || len(trace.Conditions) != 2 {
+		t.Errorf("Validator.ExplainMultipleStructs() = %v, %v", trace, err)
+	}
+	if _, err := NewProcessor().RegisterCondition(`Status =`).Explain(order); err == nil {
+		t.Errorf("Validator.Explain() error = nil, want parse error")
+	}
+}
+
+func TestValidator_Compile(t *testing.T) {
+	type Item struct {
+		Sku    string
+		Amount float64
+		Codes  []string
+	}
+	type Audit struct {
+		CreatedBy string
+	}
+	type Payload struct {
+		Status    string                 `json:"status"`
+		Amount    *float64               `json:"amount"`
+		Count     *int64                 `json:"count"`
+		PaidAt    *time.Time             `json:"paidAt"`
+		ExtraInfo map[string]interface{} `json:"extraInfo"`
+		Meta      interface{}            `json:"meta"`
+	}
+	type Event struct {
+		*Audit
+		ID        int64      `json:"id"`
+		Status    string     `json:"status"`
+		Enabled   bool       `json:"enabled"`
+		Total     float64    `json:"total"`
+		CreatedAt time.Time  `json:"createdAt"`
+		Payload   Payload    `json:"payload"`
+		Retry     *Payload   `json:"retry"`
+		Items     []Item     `json:"items"`
+		Refs      []*Item    `json:"refs"`
+		Tags      []string   `json:"tags"`
+		Note      *string    `json:"note"`
+		Children  []Event    `json:"children"`
+		Updated   *time.Time `json:"updated"`
+	}
+
+	amount, count, note := 1500.0, int64(3), "urgent"
+	paidAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			Audit:     &Audit{CreatedBy: "system"},
+			ID:        7,
+			Status:    "paid",
+			Enabled:   true,
+			Total:     1500,
+			CreatedAt: paidAt.Add(-time.Hour),
+			Payload: Payload{
+				Status:    "settled",
+				Amount:    &amount,
+				Count:     &count,
+				PaidAt:    &paidAt,
+				ExtraInfo: map[string]interface{}{"channel": "mobile", "labels": []interface{}{"vip"}},
+				Meta:      Item{Sku: "M-1"},
+			},
+			Items:    []Item{{Sku: "A-1", Amount: 500, Codes: []string{"x"}}, {Sku: "B-2", Amount: 1500, Codes: []string{"x", "y", "z"}}},
+			Refs:     []*Item{nil, {Sku: "C-3", Amount: 10}},
+			Tags:     []string{"promo", "new"},
+			Note:     &note,
+			Children: []Event{{Status: "void", Items: []Item{{Amount: 1}}}},
+		},
+		{Status: "void"},
+	}
+
+	queries := []string{
+		`Status = paid && ID >= 7 && Total = $Payload.Amount`,
+		`!(Status = void || Enabled = false) && Payload.Status |~ "^sett"`,
+		`Status in (paid, settled) && ID not in (1, 2) && Payload.Count in (3)`,
+		`CreatedBy = system && Audit.CreatedBy = system`,
+		`Payload.ExtraInfo.channel = mobile && Payload.Meta.Sku = M-1 && Note |= urg`,
+		`Retry.Status = paid || Retry.Status != paid || Unknown = 1`,
+		`CreatedAt < $Payload.PaidAt && Payload.PaidAt >= 2024-01-01T00:00:00Z && Updated > 2020-01-01T00:00:00Z`,
+		`any(Items, Items.Amount > 1000 && len(Items.Codes) = 3) && all(Tags, Tags != test)`,
+		`none(Refs, Refs.Sku = Z-9) && any(Refs, Refs.Amount = 10) && len(Payload.ExtraInfo.labels) = 1`,
+		`any(Children, Children.Status = void && any(Children.Items, Children.Items.Amount < $Total))`,
+		`any(Payload.ExtraInfo.labels, Payload.ExtraInfo.labels = vip) && len(Status) = 4`,
+		`Payload.Amount > 1000`,
+		`ID = abc`,
+	}
+	for _, query := range queries {
+		for _, jsonTag := range []bool{false, true} {
+			validator := NewProcessor().MustRegisterCondition(query)
+			if jsonTag {
+				validator.SetNameStrategy(namestrategies.JSONTag)
+			}
+			plan, err := validator.Compile(reflect.TypeOf(Event{}))
+			if err != nil {
+				t.Fatalf("Validator.Compile(%q) error = %v", query, err)
+			}
+			for i := range events {
+				for _, data := range []interface{}{events[i], &events[i]} {
+					wantIsValid, wantErr := validator.ValidateStruct(data)
+					gotIsValid, gotErr := plan.Validate(data)
+					if gotIsValid != wantIsValid || (gotErr != nil) != (wantErr != nil) {
+						t.Errorf("Plan.Validate(%q, json %v, event %d) = %v, %v, want %v, %v", query, jsonTag, i, gotIsValid, gotErr, wantIsValid, wantErr)
+					}
+				}
+			}
+		}
+	}
+
+	mapData := map[string]interface{}{"status": "paid", "payload": map[string]interface{}{"count": 3}, "tags": []string{"new"}}
+	validator := NewProcessor().MustRegisterCondition(`status = paid && payload.count = 3 && any(tags, tags = new) && prefix.status = paid`).SetRemovePrefix(true)
+	plan, err := validator.Compile(reflect.TypeOf(mapData))
+	if err != nil {
+		t.Fatalf("Validator.Compile() error = %v", err)
+	}
+	if isValid, err := plan.Validate(mapData); !isValid || err != nil {
+		t.Errorf("Plan.Validate() = %v, %v, want true", isValid, err)
+	}
+	if _, err := plan.Validate(map[string]interface{}{}); err == nil {
+		t.Errorf("Plan.Validate() error = nil, want error for empty map")
+	}
+	if _, err := plan.Validate(events[0]); err == nil {
+		t.Errorf("Plan.Validate() error = nil, want error for another type")
+	}
+	if _, err := validator.Compile(reflect.TypeOf("")); err == nil {
+		t.Errorf("Validator.Compile() error = nil, want error for string")
+	}
+	if _, err := NewProcessor().RegisterCondition(`Status =`).Compile(reflect.TypeOf(Event{})); err == nil {
+		t.Errorf("Validator.Compile() error = nil, want parse error")
+	}
+
+	condition, _ := GenerateCondition(`any(Items, Items.Amount > 1000)`)
+	plan, err = Compile(condition, reflect.TypeOf(&Event{}))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(event Event, want bool) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				if isValid, err := plan.Validate(&event); isValid != want || err != nil {
+					t.Errorf("Plan.Validate() = %v, %v, want %v", isValid, err, want)
+					return
+				}
+			}
+		}(events[i%2], i%2 == 0)
+	}
+	wg.Wait()
+}
+
+func TestGenerics(t *testing.T) {
+	type Account struct {
+		ID       int    `json:"id"`
+		Division string `json:"division"`
+	}
+	accounts := []Account{
+		{ID: 1, Division: "people"},
+		{ID: 2, Division: "finance"},
+		{ID: 3, Division: "finance"},
+	}
+	validator := NewProcessor().MustRegisterCondition(`division = finance`).SetNameStrategy(namestrategies.JSONTag)
+
+	isValid, err := Validate(validator, accounts[1])
+	if err != nil || !isValid {
+		t.Errorf("Validate() = %v, %v, want true", isValid, err)
+	}
+
+	filtered, err := Filter(validator, accounts)
+	if err != nil || !reflect.DeepEqual(filtered, accounts[1:]) {
+		t.Errorf("Filter() = %v, %v, want %v", filtered, err, accounts[1:])
+	}
+	pointers, err := Filter(validator, []*Account{&accounts[0], nil, &accounts[2]})
+	if err != nil || len(pointers) != 1 || pointers[0] != &accounts[2] {
+		t.Errorf("Filter() = %v, %v, want [%p]", pointers, err, &accounts[2])
+	}
+	objects, err := Filter(validator, []interface{}{accounts[0], map[string]interface{}{"division": "finance"}})
+	if err != nil || len(objects) != 1 {
+		t.Errorf("Filter() = %v, %v, want the map", objects, err)
+	}
+
+	matched, unmatched, err := Partition(validator, accounts)
+	if err != nil || !reflect.DeepEqual(matched, accounts[1:]) || !reflect.DeepEqual(unmatched, accounts[:1]) {
+		t.Errorf("Partition() = %v, %v, %v", matched, unmatched, err)
+	}
+
+	found, ok, err := Find(validator, accounts)
+	if err != nil || !ok || found.ID != 2 {
+		t.Errorf("Find() = %v, %v, %v, want ID 2", found, ok, err)
+	}
+	found, ok, err = Find(validator, accounts[:1])
+	if err != nil || ok || found != (Account{}) {
+		t.Errorf("Find() = %v, %v, %v, want not found", found, ok, err)
+	}
+
+	invalid := NewProcessor().RegisterCondition(`division =`)
+	if _, err := Filter(invalid, []Account{}); err == nil {
+		t.Errorf("Filter() error = nil, want parse error")
+	}
+	if result, err := invalid.FilterSlice(accounts); err == nil || result != nil {
+		t.Errorf("Validator.FilterSlice() = %v, %v, want nil and parse error", result, err)
+	}
+	if _, _, err := Partition(NewProcessor().MustRegisterCondition(`ID = abc`), accounts); err == nil {
+		t.Errorf("Partition() error = nil, want parse int error")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	type Account struct {
+		ID       int        `json:"id"`
+		Division string     `json:"division"`
+		Score    *float64   `json:"score"`
+		JoinDate time.Time  `json:"join_date"`
+		Leave    *time.Time `json:"leave"`
+	}
+	score := func(f float64) *float64 {
+		return &f
+	}
+	date := func(year int) time.Time {
+		return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	accounts := []Account{
+		{ID: 1, Division: "people", Score: score(70), JoinDate: date(2020)},
+		{ID: 2, Division: "finance", Score: score(90), JoinDate: date(2014)},
+		{ID: 3, Division: "finance", JoinDate: date(2018)},
+		{ID: 4, Division: "business", Score: score(90), JoinDate: date(2016)},
+		{ID: 5, Division: "finance", Score: score(8), JoinDate: date(2021)},
+	}
+	ids := func(result interface{}) []int {
+		var ids []int
+		for _, account := range result.([]Account) {
+			ids = append(ids, account.ID)
+		}
+		return ids
+	}
+	conditions := func(query string) []*structs2.Condition {
+		condition, _ := GenerateCondition(query)
+		return condition.Conditions
+	}
+
+	tests := []struct {
+		name    string
+		base    structs2.BaseCondition
+		wantIDs []int
+		wantErr bool
+	}{
+		{
+			name:    "Normal case - no condition keeps every item",
+			base:    structs2.BaseCondition{},
+			wantIDs: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "Normal case - filter and sort by number descending",
+			base: structs2.BaseCondition{
+				Conditions: conditions(`Division = finance || Division = people`),
+				Footer:     structs2.Footer{Sort: map[string]string{"Score": "DESC"}},
+			},
+			wantIDs: []int{2, 1, 5, 3},
+		},
+		{
+			name: "Normal case - multiple keys in order",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{
+					Sort:     map[string]string{"Score": "desc", "JoinDate": "asc"},
+					SortKeys: []string{"Score", "JoinDate"},
+				},
+			},
+			wantIDs: []int{2, 4, 1, 5, 3},
+		},
+		{
+			name: "Normal case - strings then ids",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{Sort: map[string]string{"Division": "asc", "ID": "desc"}, SortKeys: []string{"Division"}},
+			},
+			wantIDs: []int{4, 5, 3, 2, 1},
+		},
+		{
+			name: "Normal case - page and limit",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{Page: 2, Limit: 2, Sort: map[string]string{"JoinDate": "desc"}},
+			},
+			wantIDs: []int{3, 4},
+		},
+		{
+			name: "Normal case - page after the last item",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{Page: 4, Limit: 2},
+			},
+			wantIDs: nil,
+		},
+		{
+			name: "Normal case - nil values last",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{Sort: map[string]string{"Leave": "desc", "Score": "asc"}, SortKeys: []string{"Score"}},
+			},
+			wantIDs: []int{5, 1, 2, 4, 3},
+		},
+		{
+			name: "Error case - unknown sort direction",
+			base: structs2.BaseCondition{
+				Footer: structs2.Footer{Sort: map[string]string{"ID": "up"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Query(accounts, tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Query() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := ids(result); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("Query() = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+
+	result, err := Query(accounts, structs2.BaseCondition{
+		Fields:   []string{"Division", "Score"},
+		Distinct: true,
+		Footer:   structs2.Footer{Sort: map[string]string{"Division": "asc"}},
+	})
+	want := []map[string]interface{}{
+		{"Division": "business", "Score": 90.0},
+		{"Division": "finance", "Score": 90.0},
+		{"Division": "finance", "Score": nil},
+		{"Division": "finance", "Score": 8.0},
+		{"Division": "people", "Score": 70.0},
+	}
+	if err != nil || !reflect.DeepEqual(result, want) {
+		t.Errorf("Query() = %v, %v, want %v", result, err, want)
+	}
+
+	result, err = Query(accounts, structs2.BaseCondition{Fields: []string{"Division"}, Distinct: true, Footer: structs2.Footer{Limit: 2}})
+	want = []map[string]interface{}{{"Division": "people"}, {"Division": "finance"}}
+	if err != nil || !reflect.DeepEqual(result, want) {
+		t.Errorf("Query() = %v, %v, want %v", result, err, want)
+	}
+
+	result, err = Query(append(accounts, accounts[0]), structs2.BaseCondition{Distinct: true})
+	if err != nil || len(ids(result)) != 5 {
+		t.Errorf("Query() = %v, %v, want 5 distinct accounts", result, err)
+	}
+
+	type Entry struct {
+		ID       int
+		Sequence int64
+		Serial   uint64
+		Amount   json.Number
+		Exact    *big.Rat
+		Total    sql.NullInt64
+	}
+	entries := []Entry{
+		{ID: 1, Sequence: 9007199254740993, Serial: 18446744073709551615, Amount: "10", Exact: big.NewRat(1, 3), Total: sql.NullInt64{Int64: 20, Valid: true}},
+		{ID: 2, Sequence: 9007199254740992, Serial: 18446744073709551614, Amount: "9", Exact: big.NewRat(1, 4), Total: sql.NullInt64{Int64: 3, Valid: true}},
+		{ID: 3, Sequence: 9007199254740994, Serial: 1, Amount: "9.5", Exact: big.NewRat(1, 2)},
+	}
+	entryIDs := func(result interface{}) []int {
+		var ids []int
+		for _, entry := range result.([]Entry) {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+	for key, want := range map[string][]int{
+		"Sequence": {2, 1, 3},
+		"Serial":   {3, 2, 1},
+		"Amount":   {2, 3, 1},
+		"Exact":    {2, 1, 3},
+		"Total":    {2, 1, 3},
+	} {
+		result, err = Query(entries, structs2.BaseCondition{Footer: structs2.Footer{Sort: map[string]string{key: "asc"}}})
+		if err != nil || !reflect.DeepEqual(entryIDs(result), want) {
+			t.Errorf("Query() sorted by %s = %v, %v, want %v", key, result, err, want)
+		}
+	}
+	result, err = Query(append(entries, entries[2], entries[0]), structs2.BaseCondition{Distinct: true})
+	if err != nil || !reflect.DeepEqual(entryIDs(result), []int{1, 2, 3}) {
+		t.Errorf("Query() = %v, %v, want 3 distinct entries", result, err)
+	}
+
+	rows := []map[string]interface{}{{"name": "b", "age": 30}, {"name": "a", "age": 20}, {"name": "c", "age": 40}}
+	result, err = Query(rows, structs2.BaseCondition{
+		Conditions: conditions(`age > 25`),
+		Footer:     structs2.Footer{Sort: map[string]string{"name": "asc"}},
+	})
+	if err != nil || !reflect.DeepEqual(result, []map[string]interface{}{rows[0], rows[2]}) {
+		t.Errorf("Query() = %v, %v", result, err)
+	}
+
+	validator := NewProcessor().MustRegisterCondition(`division = finance`).SetNameStrategy(namestrategies.JSONTag)
+	result, err = validator.Query(accounts, structs2.BaseCondition{
+		Conditions: conditions(`score >= 10`),
+		Footer:     structs2.Footer{Sort: map[string]string{"join_date": "desc"}},
+	})
+	if err != nil || !reflect.DeepEqual(ids(result), []int{2}) {
+		t.Errorf("Validator.Query() = %v, %v, want [2]", result, err)
+	}
+
+	if _, err := Query(accounts[0], structs2.BaseCondition{}); err == nil {
+		t.Errorf("Query() error = nil, want error for a struct")
+	}
+}
+
+func TestGenerateBaseCondition(t *testing.T) {
+	type Payment struct {
+		ID     int
+		Amount float64
+		Status string
+	}
+	payments := []Payment{
+		{ID: 1, Amount: 150, Status: "active"},
+		{ID: 2, Amount: 50, Status: "active"},
+		{ID: 3, Amount: 300, Status: "active"},
+		{ID: 4, Amount: 300, Status: "closed"},
+		{ID: 5, Amount: 120, Status: "active"},
+		{ID: 6, Amount: 300, Status: "active"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  interface{}
+	}{
+		{
+			name:  "Normal case - sort, limit and offset",
+			query: `Status=active && Amount>100 | sort Amount desc, ID | limit 2 offset 1`,
+			want:  []Payment{payments[5], payments[0]},
+		},
+		{
+			name:  "Normal case - page",
+			query: `| sort ID desc | limit 4 | page 2`,
+			want:  []Payment{payments[1], payments[0]},
+		},
+		{
+			name:  "Normal case - distinct fields",
+			query: `Amount >= 150 | fields Amount | distinct | sort Amount`,
+			want:  []map[string]interface{}{{"Amount": 150.0}, {"Amount": 300.0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := GenerateBaseCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateBaseCondition() error = %v", err)
+			}
+			got, err := Query(payments, base)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidator_Precedence(t *testing.T) {
+	type Order struct {
+		Status string
+		Total  int
+		Items  []string
+	}
+	order := Order{Status: "paid", Total: 5, Items: []string{"a"}}
+
+	tests := []struct {
+		name       string
+		query      string
+		want       bool
+		wantLegacy bool
+	}{
+		{
+			name:       "Normal case - and binds tighter",
+			query:      `Status = paid || Total > 10 && Status = void`,
+			want:       true,
+			wantLegacy: false,
+		},
+		{
+			name:       "Normal case - same result",
+			query:      `Status = void && Total > 10 || Total = 5`,
+			want:       true,
+			wantLegacy: true,
+		},
+		{
+			name:       "Normal case - inside quantifier",
+			query:      `any(Items, Items = a || Items = b && Items = c)`,
+			want:       true,
+			wantLegacy: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, isLegacy := range []bool{false, true} {
+				want := tt.want
+				if isLegacy {
+					want = tt.wantLegacy
+				}
+				validator := NewProcessor().SetLegacyPrecedence(isLegacy).MustRegisterCondition(tt.query)
+				if got, err := validator.ValidateStruct(order); err != nil || got != want {
+					t.Errorf("Validator.ValidateStruct() legacy %v = %v, %v, want %v", isLegacy, got, err, want)
+				}
+				plan, err := validator.Compile(reflect.TypeOf(order))
+				if err != nil {
+					t.Fatalf("Validator.Compile() error = %v", err)
+				}
+				if got, err := plan.Validate(order); err != nil || got != want {
+					t.Errorf("Plan.Validate() legacy %v = %v, %v, want %v", isLegacy, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidator_ShortCircuit(t *testing.T) {
+	type Order struct {
+		Status string
+		Total  int
+	}
+	order := Order{Status: "paid", Total: 5}
+
+	// Total = abc fails to validate, the terms are only evaluated when they
+	// can change the result
+	tests := []struct {
+		name    string
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "Normal case - or after true", query: `Status = paid || Total = abc`, want: true},
+		{name: "Normal case - and after false", query: `Status = void && Total = abc`, want: false},
+		{name: "Normal case - group after true", query: `Status = paid || (Total = abc && Status = x)`, want: true},
+		{name: "Error case - evaluated", query: `Status = void || Total = abc`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query)
+			got, err := validator.ValidateStruct(order)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Validator.ValidateStruct() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+			plan, _ := validator.Compile(reflect.TypeOf(order))
+			got, err = plan.Validate(order)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Plan.Validate() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	trace, err := NewProcessor().MustRegisterCondition(`Status = paid || Total = abc`).Explain(order)
+	want := `true    group
+  true    Status = paid [field: "paid", value: "paid"]
+  skipped OR Total = abc
+`
+	if err != nil || trace.String() != want {
+		t.Errorf("Validator.Explain() = \n%v, %v, want \n%v", trace, err, want)
+	}
+}
+
+func TestValidator_Errors(t *testing.T) {
+	type Order struct {
+		Status string
+		Total  int
+	}
+	order := Order{Status: "paid", Total: 5}
+
+	tests := []struct {
+		name     string
+		validate func() error
+		sentinel error
+		check    func(err error) bool
+	}{
+		{
+			name: "Error case - nil data",
+			validate: func() error {
+				_, err := NewProcessor().MustRegisterCondition(`Status = paid`).ValidateStruct(nil)
+				return err
+			},
+			sentinel: ErrInvalidInput,
+			check: func(err error) bool {
+				var inputErr *InvalidInputError
+				return errors.As(err, &inputErr) && inputErr.Type == nil && err.Error() == "data can't be nil"
+			},
+		},
+		{
+			name: "Error case - not a struct",
+			validate: func() error {
+				_, err := NewProcessor().MustRegisterCondition(`Status = paid`).ValidateStruct(1)
+				return err
+			},
+			sentinel: ErrInvalidInput,
+			check: func(err error) bool {
+				var inputErr *InvalidInputError
+				return errors.As(err, &inputErr) && inputErr.Type == reflect.TypeOf(1) && inputErr.Expected == "struct"
+			},
+		},
+		{
+			name: "Error case - map keys",
+			validate: func() error {
+				_, err := NewProcessor().MustRegisterCondition(`Status = paid`).ValidateStruct(map[int]string{1: "paid"})
+				return err
+			},
+			sentinel: ErrInvalidInput,
+		},
+		{
+			name: "Error case - filter a struct",
+			validate: func() error {
+				_, err := NewProcessor().MustRegisterCondition(`Status = paid`).FilterSlice(order)
+				return err
+			},
+			sentinel: ErrInvalidInput,
+		},
+		{
+			name: "Error case - literal of another type",
+			validate: func() error {
+				_, err := NewProcessor().MustRegisterCondition(`Total = abc`).ValidateStruct(order)
+				return err
+			},
+			sentinel: ErrTypeMismatch,
+			check: func(err error) bool {
+				var mismatch *TypeMismatchError
+				var numErr *strconv.NumError
+				return errors.As(err, &mismatch) && mismatch.Path == "Total" && mismatch.FieldType == reflect.TypeOf(0) &&
+					mismatch.Literal == "abc" && errors.As(err, &numErr)
+			},
+		},
+		{
+			name: "Error case - literal of another type in a plan",
+			validate: func() error {
+				condition, _ := GenerateCondition(`Total = abc`)
+				plan, err := Compile(condition, reflect.TypeOf(order))
+				if err != nil {
+					return err
+				}
+				_, err = plan.Validate(order)
+				return err
+			},
+			sentinel: ErrTypeMismatch,
+		},
+		{
+			name: "Error case - parse error",
+			validate: func() error {
+				_, err := NewProcessor().RegisterCondition(`Status = (paid`).ValidateStruct(order)
+				return err
+			},
+			sentinel: ErrParse,
+			check: func(err error) bool {
+				var parseErr *ParseError
+				return errors.As(err, &parseErr) && parseErr.Column == 10
+			},
+		},
+		{
+			name: "Error case - unknown quantifier",
+			validate: func() error {
+				condition := structs2.Condition{Conditions: []*structs2.Condition{{Quantifier: "most", Collection: "Items"}}}
+				_, err := validators.NewConditionValidator(&condition).Validate(order)
+				return err
+			},
+			sentinel: ErrInvalidCondition,
+		},
+		{
+			name: "Error case - no condition",
+			validate: func() error {
+				v := &validator{conditionValidator: validators.NewConditionValidator(nil)}
+				_, err := v.ValidateStruct(order)
+				return err
+			},
+			sentinel: ErrInvalidCondition,
+		},
+		{
+			name: "Error case - plan of another type",
+			validate: func() error {
+				condition, _ := GenerateCondition(`Status = paid`)
+				plan, _ := Compile(condition, reflect.TypeOf(order))
+				_, err := plan.Validate(struct{ Status string }{})
+				return err
+			},
+			sentinel: ErrInvalidInput,
+		},
+	}
+	sentinels := []error{ErrParse, ErrInvalidCondition, ErrInvalidInput, ErrTypeMismatch, ErrUnknownAttribute}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate()
+			for _, sentinel := range sentinels {
+				if errors.Is(err, sentinel) != (sentinel == tt.sentinel) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, sentinel, !(sentinel == tt.sentinel))
+				}
+			}
+			if tt.check != nil && !tt.check(err) {
+				t.Errorf("error = %#v", err)
+			}
+		})
+	}
+}
+
+type nullPayload struct {
+	Partner string
+}
+
+type nullEvent struct {
+	Status     string
+	SenderType *string
+	Amount     *int
+	Payload    *nullPayload
+	Tags       []string
+}
+
+func TestValidator_NullPredicates(t *testing.T) {
+	bca := "bca"
+	empty := nullEvent{Status: "paid"}
+	filled := nullEvent{Status: "paid", SenderType: &bca, Payload: &nullPayload{Partner: "x"}}
+	event := map[string]interface{}{"SenderType": nil, "Partner": "bca"}
+
+	tests := []struct {
+		name  string
+		query string
+		data  interface{}
+		want  bool
+	}{
+		{name: "Normal case - nil field exists", query: `exists(SenderType)`, data: empty, want: true},
+		{name: "Normal case - nil field is null", query: `SenderType is null`, data: empty, want: true},
+		{name: "Normal case - set field is not null", query: `SenderType is not null`, data: filled, want: true},
+		{name: "Normal case - set field is null", query: `SenderType is null`, data: filled, want: false},
+		{name: "Normal case - path through nil doesn't exist", query: `exists(Payload.Partner)`, data: empty, want: false},
+		{name: "Normal case - path through nil is null", query: `Payload.Partner is null`, data: empty, want: true},
+		{name: "Normal case - path exists", query: `exists(Payload.Partner) && Payload.Partner is not null`, data: filled, want: true},
+		{name: "Normal case - nil map value exists", query: `exists(SenderType) && SenderType is null`, data: event, want: true},
+		{name: "Normal case - missing key", query: `!exists(Missing) && Missing is null`, data: event, want: true},
+		{name: "Normal case - map value is not null", query: `Partner is not null`, data: event, want: true},
+		{name: "Normal case - not equal to nil is false", query: `SenderType != bca`, data: empty, want: false},
+		{name: "Normal case - negated equal to nil is true", query: `!(SenderType = bca)`, data: empty, want: true},
+		{name: "Normal case - null or not equal", query: `SenderType is null || SenderType != bca`, data: empty, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query)
+			got, err := validator.ValidateStruct(tt.data)
+			if err != nil || got != tt.want {
+				t.Errorf("Validator.ValidateStruct() = %v, %v, want %v", got, err, tt.want)
+			}
+			plan, err := validator.Compile(reflect.TypeOf(tt.data))
+			if err != nil {
+				t.Fatalf("Validator.Compile() error = %v", err)
+			}
+			got, err = plan.Validate(tt.data)
+			if err != nil || got != tt.want {
+				t.Errorf("Plan.Validate() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	type Sender struct {
+		Name string
+	}
+	type Transfer struct {
+		Status string
+		Bank   *string
+	}
+	validator := NewProcessor().MustRegisterCondition(`exists(Bank) && Bank is null && exists(Name)`)
+	if got, err := validator.ValidateMultipleStructs(Transfer{Status: "paid"}, Sender{Name: "reza"}); err != nil || !got {
+		t.Errorf("Validator.ValidateMultipleStructs() = %v, %v, want true", got, err)
+	}
+
+	input, _ := GenerateCondition(`Status = paid`)
+	validator = NewProcessor().MustRegisterCondition(`SenderType is null && Status is not null && exists(Status)`)
+	if got, err := validator.ValidateCondition(input); err != nil || !got {
+		t.Errorf("Validator.ValidateCondition() = %v, %v, want true", got, err)
+	}
+}
+
+func TestValidator_NullPolicy(t *testing.T) {
+	bca, five := "bca", 5
+	empty := nullEvent{Status: "paid"}
+	filled := nullEvent{Status: "paid", SenderType: &bca, Amount: &five}
+	event := map[string]interface{}{"Status": "paid", "SenderType": nil}
+
+	tests := []struct {
+		name    string
+		policy  nullpolicies.NullPolicy
+		query   string
+		data    interface{}
+		want    bool
+		wantErr error
+	}{
+		{name: "Normal case - false, not equal", policy: nullpolicies.False, query: `SenderType != bca`, data: empty, want: false},
+		{name: "Normal case - false, negated", policy: nullpolicies.False, query: `!(SenderType = bca)`, data: empty, want: true},
+		{name: "Normal case - false, missing key", policy: nullpolicies.False, query: `!(Missing = 1)`, data: event, want: true},
+		{name: "Normal case - zero value, not equal", policy: nullpolicies.ZeroValue, query: `SenderType != bca`, data: empty, want: true},
+		{name: "Normal case - zero value, empty string", policy: nullpolicies.ZeroValue, query: `SenderType = "" && Amount = 0 && Amount < 1`, data: empty, want: true},
+		{name: "Normal case - zero value, set field", policy: nullpolicies.ZeroValue, query: `SenderType = bca && Amount = 5`, data: filled, want: true},
+		{name: "Normal case - zero value, missing key", policy: nullpolicies.ZeroValue, query: `Missing = 0 && Other != bca && SenderType in ("", x) && len(Missing) = 0`, data: event, want: true},
+		{name: "Normal case - zero value, nil collection", policy: nullpolicies.ZeroValue, query: `all(Missing, Missing = x) && !any(Missing, Missing = x)`, data: event, want: true},
+		{name: "Normal case - unknown, not equal", policy: nullpolicies.Unknown, query: `SenderType != bca`, data: empty, want: false},
+		{name: "Normal case - unknown, negated", policy: nullpolicies.Unknown, query: `!(SenderType = bca)`, data: empty, want: false},
+		{name: "Normal case - unknown or true", policy: nullpolicies.Unknown, query: `SenderType = bca || Status = paid`, data: empty, want: true},
+		{name: "Normal case - unknown and false", policy: nullpolicies.Unknown, query: `!(SenderType = bca && Status = void)`, data: empty, want: true},
+		{name: "Normal case - unknown and true", policy: nullpolicies.Unknown, query: `!(SenderType = bca && Status = paid)`, data: empty, want: false},
+		{name: "Normal case - unknown element", policy: nullpolicies.Unknown, query: `!any(Items, Items.Amount > 1)`, data: map[string]interface{}{"Items": []interface{}{map[string]interface{}{"Amount": nil}}}, want: false},
+		{name: "Normal case - unknown, null predicate", policy: nullpolicies.Unknown, query: `SenderType is null && !exists(Payload.Partner)`, data: empty, want: true},
+		{name: "Normal case - error, set field", policy: nullpolicies.Error, query: `SenderType = bca`, data: filled, want: true},
+		{name: "Normal case - error, short circuit", policy: nullpolicies.Error, query: `Status = paid || SenderType = bca`, data: empty, want: true},
+		{name: "Normal case - error, null predicate", policy: nullpolicies.Error, query: `SenderType is null && !exists(Missing)`, data: event, want: true},
+		{name: "Error case - error, nil field", policy: nullpolicies.Error, query: `SenderType != bca`, data: empty, wantErr: &UnknownAttributeError{Path: "SenderType", IsNull: true}},
+		{name: "Error case - error, missing key", policy: nullpolicies.Error, query: `Missing = 1`, data: event, wantErr: &UnknownAttributeError{Path: "Missing"}},
+		{name: "Error case - error, nil reference", policy: nullpolicies.Error, query: `Status = $SenderType`, data: empty, wantErr: &UnknownAttributeError{Path: "SenderType", IsNull: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query).SetNullPolicy(tt.policy)
+			plan, err := validator.Compile(reflect.TypeOf(tt.data))
+			if err != nil {
+				t.Fatalf("Validator.Compile() error = %v", err)
+			}
+			for _, validate := range []func(data interface{}) (bool, error){validator.ValidateStruct, plan.Validate} {
+				got, err := validate(tt.data)
+				if !reflect.DeepEqual(err, tt.wantErr) || got != tt.want {
+					t.Errorf("Validate() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+				}
+				if tt.wantErr != nil && !errors.Is(err, ErrUnknownAttribute) {
+					t.Errorf("errors.Is(%v, ErrUnknownAttribute) = false", err)
+				}
+			}
+		})
+	}
+
+	trace, err := NewProcessor().MustRegisterCondition(`!SenderType = bca && Status = paid`).
+		SetNullPolicy(nullpolicies.Unknown).Explain(empty)
+	want := `unknown group
+  unknown NOT SenderType = bca [field: nil, value: nil]
+  true    AND Status = paid [field: "paid", value: "paid"]
+`
+	if err != nil || trace.String() != want {
+		t.Errorf("Validator.Explain() = \n%v, %v, want \n%v", trace, err, want)
+	}
+}
+
+type coercionStatus string
+
+type coercionLevel int
+
+func (l coercionLevel) String() string {
+	return "level-" + strconv.Itoa(int(l))
+}
+
+type coercionCode struct {
+	Prefix string
+	Number int
+}
+
+func (c coercionCode) String() string {
+	return c.Prefix + "-" + strconv.Itoa(c.Number)
+}
+
+type coercionTag struct {
+	Name string
+}
+
+func (t *coercionTag) MarshalText() ([]byte, error) {
+	return []byte("tag:" + t.Name), nil
+}
+
+type coercionMoney struct {
+	Units int64
+	Cents int64
+}
+
+type coercionEvent struct {
+	Count      int32
+	Sequence   uint64
+	Ratio      float32
+	Rate       *float64
+	Status     coercionStatus
+	StatusPtr  *coercionStatus
+	Level      coercionLevel
+	Statuses   []coercionStatus
+	Number     json.Number
+	Name       sql.NullString
+	Total      sql.NullInt64
+	PaidAt     sql.NullTime
+	Code       coercionCode
+	Tag        coercionTag
+	Price      coercionMoney
+	Limit      int64
+	OtherRatio float32
+}
+
+func TestValidator_Coercion(t *testing.T) {
+	RegisterConverter(func(m coercionMoney) (interface{}, error) {
+		if m.Cents < 0 {
+			return nil, errors.New("negative cents")
+		}
+		return m.Units*100 + m.Cents, nil
+	})
+
+	rate, status := 0.25, coercionStatus("paid")
+	paidAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := coercionEvent{
+		Count:      7,
+		Sequence:   18446744073709551615,
+		Ratio:      0.1,
+		Rate:       &rate,
+		Status:     "paid",
+		StatusPtr:  &status,
+		Level:      2,
+		Statuses:   []coercionStatus{"paid", "void"},
+		Number:     "12.5",
+		Name:       sql.NullString{String: "bca", Valid: true},
+		Total:      sql.NullInt64{Int64: 250, Valid: true},
+		PaidAt:     sql.NullTime{Time: paidAt, Valid: true},
+		Code:       coercionCode{Prefix: "INV", Number: 42},
+		Tag:        coercionTag{Name: "vip"},
+		Price:      coercionMoney{Units: 10, Cents: 50},
+		Limit:      7,
+		OtherRatio: 0.1,
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		data    interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "Normal case - int32", query: `Count = 7 && Count > 6 && Count in (1, 7)`, data: event, want: true},
+		{name: "Normal case - uint64", query: `Sequence = 18446744073709551615 && Sequence > 18446744073709551614 && Sequence in (18446744073709551615)`, data: event, want: true},
+		{name: "Normal case - float32", query: `Ratio = 0.1 && Ratio < 0.2 && Ratio in (0.1, 0.3)`, data: event, want: true},
+		{name: "Normal case - float pointer", query: `Rate = 0.25 && Rate > 0.2 && Rate <= 0.25`, data: event, want: true},
+		{name: "Normal case - named string", query: `Status = paid && Status |= ai && Status in (paid, void) && StatusPtr = paid`, data: event, want: true},
+		{name: "Normal case - named string element", query: `any(Statuses, Statuses = void)`, data: event, want: true},
+		{name: "Normal case - stringer of a numeric kind", query: `Level = 2 && Level >= 2`, data: event, want: true},
+		{name: "Normal case - json number", query: `Number = 12.5 && Number > 12`, data: event, want: true},
+		{name: "Normal case - sql null types", query: `Name = bca && Total > 200 && PaidAt = 2024-01-02T03:04:05Z`, data: event, want: true},
+		{name: "Normal case - invalid sql null type", query: `Name is null && !(Name = bca)`, data: coercionEvent{}, want: true},
+		{name: "Normal case - stringer", query: `Code = INV-42 && Code |= INV`, data: event, want: true},
+		{name: "Normal case - text marshaler", query: `Tag = "tag:vip"`, data: event, want: true},
+		{name: "Normal case - converter", query: `Price = 1050 && Price > 1000`, data: event, want: true},
+		{name: "Normal case - field reference across kinds", query: `Count = $Limit && Limit = $Count && Ratio = $OtherRatio`, data: event, want: true},
+		{name: "Normal case - map values", query: `Count = 7 && Status = paid`, data: map[string]interface{}{"Count": int8(7), "Status": coercionStatus("paid")}, want: true},
+		{name: "Error case - uint64 with a negative literal", query: `Sequence > -1`, data: event, wantErr: true},
+		{name: "Error case - float pointer with a text", query: `Rate = abc`, data: event, wantErr: true},
+		{name: "Error case - converter error", query: `Price = 1050`, data: coercionEvent{Price: coercionMoney{Cents: -1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query)
+			plan, err := validator.Compile(reflect.TypeOf(tt.data))
+			if err != nil {
+				t.Fatalf("Validator.Compile() error = %v", err)
+			}
+			for _, validate := range []func(data interface{}) (bool, error){validator.ValidateStruct, plan.Validate} {
+				got, err := validate(tt.data)
+				if (err != nil) != tt.wantErr || got != tt.want {
+					t.Errorf("Validate() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+				}
+				if tt.wantErr && !errors.Is(err, ErrTypeMismatch) {
+					t.Errorf("errors.Is(%v, ErrTypeMismatch) = false", err)
+				}
+			}
+		})
+	}
+}
+
+type decimalStatement struct {
+	Debit       float64
+	TotalAmount float64
+	Ratio       float32
+	Balance     int64
+	Sequence    uint64
+	AmountText  string
+	Name        string
+	Exact       *big.Rat
+	Big         *big.Int
+	Number      json.Number
+	Missing     *big.Rat
+}
+
+func TestValidator_NumericMode(t *testing.T) {
+	big2to70, _ := new(big.Int).SetString("1180591620717411303424", 10)
+	statement := decimalStatement{
+		Debit:       0.1,
+		TotalAmount: 0.1,
+		Ratio:       0.1,
+		Balance:     9007199254740993,
+		Sequence:    18446744073709551615,
+		AmountText:  "1500.25",
+		Name:        "bca",
+		Exact:       big.NewRat(1, 3),
+		Big:         big2to70,
+		Number:      "12345678901234567.89",
+	}
+
+	tests := []struct {
+		name    string
+		mode    numericmodes.NumericMode
+		query   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "Normal case - float, float64", mode: numericmodes.Float, query: `Debit = 0.1 && Debit < 0.11`, want: true},
+		{name: "Normal case - float, decimal string", mode: numericmodes.Float, query: `AmountText = 1500.250`, want: false},
+		{name: "Normal case - float, big types", mode: numericmodes.Float, query: `Exact = 1/3 && Exact > 0.3333 && Big = 1180591620717411303424 && Number = 12345678901234567.89`, want: true},
+		{name: "Normal case - decimal, float64", mode: numericmodes.Decimal, query: `Debit = 0.1 && Debit = 0.10 && Debit < 0.11 && Debit = $TotalAmount`, want: true},
+		{name: "Normal case - decimal, float32", mode: numericmodes.Decimal, query: `Ratio = 0.1 && Ratio in (0.10, 5)`, want: true},
+		{name: "Normal case - decimal, int64 beyond 2^53", mode: numericmodes.Decimal, query: `Balance = 9007199254740993.0 && Balance > 9007199254740992 && Balance != 9007199254740992`, want: true},
+		{name: "Normal case - decimal, uint64", mode: numericmodes.Decimal, query: `Sequence > 18446744073709551614.5 && Sequence in (18446744073709551615)`, want: true},
+		{name: "Normal case - decimal, decimal string", mode: numericmodes.Decimal, query: `AmountText = 1500.250 && AmountText > 1500.2 && AmountText in (1500.25) && AmountText |= 500`, want: true},
+		{name: "Normal case - decimal, text string", mode: numericmodes.Decimal, query: `Name = bca && Name != 1`, want: true},
+		{name: "Normal case - decimal, big types", mode: numericmodes.Decimal, query: `Exact = 1/3 && Exact < 0.3334 && Big >= 1180591620717411303424 && Number = 12345678901234567.890 && Number = $Number && Exact = $Exact`, want: true},
+		{name: "Normal case - decimal, nil big rat", mode: numericmodes.Decimal, query: `Missing is null && !(Missing = 0)`, want: true},
+		{name: "Error case - decimal, not a number", mode: numericmodes.Decimal, query: `Debit = abc`, wantErr: true},
+		{name: "Error case - decimal, exponent too large", mode: numericmodes.Decimal, query: `Balance < 1e1000000`, wantErr: true},
+		{name: "Error case - float, decimal literal on an int", mode: numericmodes.Float, query: `Balance = 9007199254740993.0`, wantErr: true},
+		{name: "Error case - float, big rat with a text", mode: numericmodes.Float, query: `Exact > abc`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewProcessor().MustRegisterCondition(tt.query).SetNumericMode(tt.mode)
+			plan, err := validator.Compile(reflect.TypeOf(statement))
+			if err != nil {
+				t.Fatalf("Validator.Compile() error = %v", err)
+			}
+			for _, validate := range []func(data interface{}) (bool, error){validator.ValidateStruct, plan.Validate} {
+				got, err := validate(statement)
+				if (err != nil) != tt.wantErr || got != tt.want {
+					t.Errorf("Validate() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+				}
+				if tt.wantErr && !errors.Is(err, ErrTypeMismatch) {
+					t.Errorf("errors.Is(%v, ErrTypeMismatch) = false", err)
+				}
+			}
+		})
+	}
+
+	conditions := []struct {
+		input   string
+		want    bool
+		wantErr bool
+	}{
+		{input: `price = 1200.51`, want: true},
+		{input: `price = 1200.50`, want: false},
+		{input: `price = 1200.500000000000001`, want: true},
+		{input: `price = abc`, wantErr: true},
+		{input: `id = 1`, want: false},
+	}
+	validator := NewProcessor().MustRegisterCondition(`price > 1200.50`).SetNumericMode(numericmodes.Decimal)
+	for _, tt := range conditions {
+		inputCondition, err := GenerateCondition(tt.input)
+		if err != nil {
+			t.Fatalf("GenerateCondition(%s) error = %v", tt.input, err)
+		}
+		got, err := validator.ValidateCondition(inputCondition)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Validator.ValidateCondition(%s) = %v, %v, want %v, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("errors.Is(%v, ErrTypeMismatch) = false", err)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package ruleset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/functions"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"github.com/ahmadrezamusthafa/deep-validator/validators"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Rule is a named condition of a rule set, given either as a query or as a
+// condition tree. LegacyPrecedence parses the query left to right, see
+// StructGen.LegacyPrecedence.
+type Rule struct {
+	ID               string                 `json:"id" yaml:"id"`
+	Description      string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Version          string                 `json:"version,omitempty" yaml:"version,omitempty"`
+	Query            string                 `json:"query,omitempty" yaml:"query,omitempty"`
+	Condition        *structs.Condition     `json:"condition,omitempty" yaml:"condition,omitempty"`
+	LegacyPrecedence bool                   `json:"legacyPrecedence,omitempty" yaml:"legacyPrecedence,omitempty"`
+	Enabled          *bool                  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Tags             []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	index     int
+	validator validators.ConditionValidator
+	plans     *sync.Map
+}
+
+// validate validates data with the plan compiled for its type, or with the
+// validator when there is none.
+func (r *Rule) validate(data interface{}) (bool, error) {
+	if data == nil {
+		return r.validator.Validate(data)
+	}
+	rType := reflect.TypeOf(data)
+	plan, ok := r.plans.Load(rType)
+	if !ok {
+		compiled, err := r.validator.Compile(rType)
+		if err != nil {
+			compiled = nil
+		}
+		plan, _ = r.plans.LoadOrStore(rType, compiled)
+	}
+	if plan := plan.(*validators.Plan); plan != nil {
+		return plan.Validate(data)
+	}
+	return r.validator.Validate(data)
+}
+
+// IsEnabled tells whether the rule takes part in evaluations, rules are
+// enabled unless they say otherwise.
+func (r *Rule) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// MatchedRule is a rule the evaluated data satisfies.
+type MatchedRule struct {
+	*Rule
+}
+
+// RuleError is the error of one rule, Index is its position in the document.
+type RuleError struct {
+	Index int
+	ID    string
+	Err   error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("rule %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Errors collects the errors of the rules of a rule set.
+type Errors []*RuleError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// document is the layout of a rule set file.
+type document struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+type RuleSet struct {
+	rules []*Rule
+	ids   map[string]*Rule
+	index *validators.Index
+}
+
+// LoadJSON loads a rule set from a JSON document of the form
+// `{"rules": [{"id": "...", "query": "..."}, ...]}`. Every rule is parsed up
+// front, the rules that can't be are left out of the rule set and their
+// errors returned together as Errors next to it.
+func LoadJSON(data []byte) (*RuleSet, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return New(doc.Rules)
+}
+
+// LoadYAML loads a rule set from a YAML document laid out like the one of
+// LoadJSON.
+func LoadYAML(data []byte) (*RuleSet, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return New(doc.Rules)
+}
+
+// LoadFile loads a rule set from a .json, .yaml or .yml file.
+func LoadFile(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(data)
+	case ".yaml", ".yml":
+		return LoadYAML(data)
+	}
+	return nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("rule set file %q", path))
+}
+
+// New builds a rule set from rules, parsing every rule the way LoadJSON does.
+func New(rules []*Rule) (*RuleSet, error) {
+	r := &RuleSet{
+		ids: make(map[string]*Rule),
+	}
+	var errs Errors
+	for i, rule := range rules {
+		if rule == nil {
+			errs = append(errs, &RuleError{Index: i, Err: fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")})
+			continue
+		}
+		rule.index = i
+		if err := r.add(rule); err != nil {
+			errs = append(errs, &RuleError{Index: i, ID: rule.ID, Err: err})
+		}
+	}
+	conditions := make([]*structs.Condition, len(r.rules))
+	for i, rule := range r.rules {
+		conditions[i] = rule.validator.GetCondition()
+	}
+	r.index = validators.NewIndex(conditions)
+	if len(errs) > 0 {
+		return r, errs
+	}
+	return r, nil
+}
+
+func (r *RuleSet) add(rule *Rule) error {
+	if rule.ID == "" {
+		return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "id")
+	}
+	if _, ok := r.ids[rule.ID]; ok {
+		return errors.New("duplicate rule id")
+	}
+	condition := rule.Condition
+	switch {
+	case rule.Query != "" && condition != nil:
+		return errors.New("rule has both a query and a condition")
+	case rule.Query != "":
+		gen := structgen.StructGen{LegacyPrecedence: rule.LegacyPrecedence}
+		parsed, err := gen.GenerateCondition(rule.Query)
+		if err != nil {
+			return err
+		}
+		condition = &parsed
+	case condition == nil:
+		return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "query or condition")
+	default:
+		if err := checkCondition(condition); err != nil {
+			return err
+		}
+	}
+	rule.validator = validators.NewConditionValidator(condition)
+	rule.plans = new(sync.Map)
+	r.rules = append(r.rules, rule)
+	r.ids[rule.ID] = rule
+	return nil
+}
+
+// checkCondition checks a condition tree given instead of a query for what
+// the parser would have rejected: unknown operators, quantifiers and
+// functions, missing names and values, and values not of their type.
+func checkCondition(condition *structs.Condition) error {
+	if condition.IsEmpty() && (condition.Attribute == nil || condition.Attribute.Operator == "") {
+		return nil
+	}
+	switch condition.Operator {
+	case "", logicaloperators.LogicalOperatorAnd, logicaloperators.LogicalOperatorOr:
+	default:
+		return fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("logical operator %q", condition.Operator))
+	}
+	switch condition.Quantifier {
+	case "":
+	case quantifiers.QuantifierAny, quantifiers.QuantifierAll, quantifiers.QuantifierNone:
+		if condition.Collection == "" {
+			return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, fmt.Sprintf("collection of %s", condition.Quantifier))
+		}
+	default:
+		return fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("quantifier %s", condition.Quantifier))
+	}
+	if condition.Attribute != nil && (condition.Attribute.Name != "" || condition.Attribute.Operator != "") {
+		if err := checkAttribute(condition.Attribute); err != nil {
+			return err
+		}
+	}
+	for _, subCondition := range condition.Conditions {
+		if subCondition == nil {
+			return fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+		}
+		if err := checkCondition(subCondition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAttribute(attribute *structs.Attribute) error {
+	if attribute.Name == "" {
+		return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "attribute name")
+	}
+	if attribute.Function != "" && attribute.Function != functions.FunctionLen {
+		return fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("function %s", attribute.Function))
+	}
+	values := []string{attribute.Value}
+	switch attribute.Operator {
+	case operators.OperatorExists, operators.OperatorIsNull, operators.OperatorIsNotNull:
+		return nil
+	case operators.OperatorIn, operators.OperatorNotIn:
+		if len(attribute.Values) == 0 {
+			return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, fmt.Sprintf("values of %s", attribute.Name))
+		}
+		values = attribute.Values
+	case operators.OperatorEqual, operators.OperatorNotEqual, operators.OperatorLessThan, operators.OperatorLessThanEqual,
+		operators.OperatorGreaterThan, operators.OperatorGreaterThanEqual, operators.OperatorContains, operators.OperatorContainsRegexMatch:
+	default:
+		return fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", attribute.Operator))
+	}
+	switch attribute.Type {
+	case "", valuetypes.Alphanumeric:
+	case valuetypes.Numeric, valuetypes.Date:
+		for _, value := range values {
+			if structgen.ValueType(value) != attribute.Type {
+				return fmt.Errorf(errormessages.ErrorMessageInvalidType, fmt.Sprintf("%s value for %s", attribute.Type, attribute.Name))
+			}
+		}
+	case valuetypes.Field:
+		if attribute.Value == "" || len(values) > 1 {
+			return fmt.Errorf(errormessages.ErrorMessageInvalidParameter, fmt.Sprintf("field name for %s", attribute.Name))
+		}
+	default:
+		return fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("value type %q", attribute.Type))
+	}
+	return nil
+}
+
+// Rules returns the rules of the rule set in document order.
+func (r *RuleSet) Rules() []*Rule {
+	return r.rules
+}
+
+// Rule returns the rule with id.
+func (r *RuleSet) Rule(id string) (*Rule, bool) {
+	rule, ok := r.ids[id]
+	return rule, ok
+}
+
+// SetRemovePrefix sets the prefix removal of the validators of every rule,
+// see Validator.SetRemovePrefix.
+func (r *RuleSet) SetRemovePrefix(value bool) *RuleSet {
+	for _, rule := range r.rules {
+		rule.validator.SetRemovePrefix(value)
+		rule.plans = new(sync.Map)
+	}
+	r.index.SetRemovePrefix(value)
+	return r
+}
+
+// SetNameStrategy sets the name strategy of the validators of every rule.
+func (r *RuleSet) SetNameStrategy(strategy namestrategies.NameStrategy) *RuleSet {
+	for _, rule := range r.rules {
+		rule.validator.SetNameStrategy(strategy)
+		rule.plans = new(sync.Map)
+	}
+	r.index.SetNameStrategy(strategy)
+	return r
+}
+
+// SetNameTag sets the name tag of the validators of every rule.
+func (r *RuleSet) SetNameTag(tagKey string) *RuleSet {
+	for _, rule := range r.rules {
+		rule.validator.SetNameTag(tagKey)
+		rule.plans = new(sync.Map)
+	}
+	r.index.SetNameTag(tagKey)
+	return r
+}
+
+// SetNullPolicy sets the null policy of the validators of every rule, see
+// Validator.SetNullPolicy.
+func (r *RuleSet) SetNullPolicy(policy nullpolicies.NullPolicy) *RuleSet {
+	for _, rule := range r.rules {
+		rule.validator.SetNullPolicy(policy)
+		rule.plans = new(sync.Map)
+	}
+	r.index.SetNullPolicy(policy)
+	return r
+}
+
+// SetNumericMode sets the numeric mode of the validators of every rule, see
+// Validator.SetNumericMode.
+func (r *RuleSet) SetNumericMode(mode numericmodes.NumericMode) *RuleSet {
+	for _, rule := range r.rules {
+		rule.validator.SetNumericMode(mode)
+		rule.plans = new(sync.Map)
+	}
+	r.index.SetNumericMode(mode)
+	return r
+}
+
+// Evaluate validates data against every enabled rule and returns the ones
+// it satisfies in document order. A rule that fails to validate data doesn't
+// match, use EvaluateE to get those errors.
+func (r *RuleSet) Evaluate(data interface{}) []MatchedRule {
+	matched, _ := r.EvaluateE(data)
+	return matched
+}
+
+// EvaluateE is Evaluate also returning the errors of the rules that failed
+// to validate data as Errors.
+func (r *RuleSet) EvaluateE(data interface{}) ([]MatchedRule, error) {
+	var matched []MatchedRule
+	var errs Errors
+	for _, rule := range r.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+		isValid, err := rule.validate(data)
+		if err != nil {
+			errs = append(errs, &RuleError{Index: rule.index, ID: rule.ID, Err: err})
+			continue
+		}
+		if isValid {
+			matched = append(matched, MatchedRule{Rule: rule})
+		}
+	}
+	if len(errs) > 0 {
+		return matched, errs
+	}
+	return matched, nil
+}
+
+// Match returns the ids of the enabled rules data satisfies in document
+// order, like Evaluate. Only the rules the index of the rule set can't rule
+// out are validated. The index walks the condition tree of every rule for an
+// `=` or `in` comparison it requires, it doesn't use the attribute names of
+// the parser. Each of those paths is resolved once, and a rule whose
+// comparison can't hold for the value found is skipped. The other rules are
+// validated through their own plan, which resolves data again, the indexed
+// path included. A rule that fails to validate data doesn't match, use
+// MatchE to get those errors.
+func (r *RuleSet) Match(data interface{}) []string {
+	ids, _ := r.MatchE(data)
+	return ids
+}
+
+// MatchE is Match also returning the errors of the validated rules that
+// failed to validate data as Errors. Skipped rules report no error.
+func (r *RuleSet) MatchE(data interface{}) ([]string, error) {
+	var ids []string
+	var errs Errors
+	for _, i := range r.index.Candidates(data) {
+		rule := r.rules[i]
+		if !rule.IsEnabled() {
+			continue
+		}
+		isValid, err := rule.validate(data)
+		if err != nil {
+			errs = append(errs, &RuleError{Index: rule.index, ID: rule.ID, Err: err})
+			continue
+		}
+		if isValid {
+			ids = append(ids, rule.ID)
+		}
+	}
+	if len(errs) > 0 {
+		return ids, errs
+	}
+	return ids, nil
+}
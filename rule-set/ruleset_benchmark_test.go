@@ -0,0 +1,41 @@
+package ruleset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func benchmarkRuleSet(b *testing.B) *RuleSet {
+	var definitions []*Rule
+	for i := 0; i < 300; i++ {
+		definitions = append(definitions, &Rule{
+			ID:    fmt.Sprintf("rule-%d", i),
+			Query: fmt.Sprintf(`Partner = partner-%d && Status in (paid, refunded) && Total >= %d`, i%100, i),
+		})
+	}
+	rules, err := New(definitions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rules
+}
+
+// BENCHMARK EvaluateE
+// 300 rules validated one by one.
+func BenchmarkEvaluate(b *testing.B) {
+	rules := benchmarkRuleSet(b)
+	event := order{Status: "paid", Partner: "partner-42", Total: 150}
+	for n := 0; n < b.N; n++ {
+		_, _ = rules.EvaluateE(event)
+	}
+}
+
+// BENCHMARK MatchE
+// Same rules and event as BenchmarkEvaluate, narrowed down by the index.
+func BenchmarkMatch(b *testing.B) {
+	rules := benchmarkRuleSet(b)
+	event := order{Status: "paid", Partner: "partner-42", Total: 150}
+	for n := 0; n < b.N; n++ {
+		_, _ = rules.MatchE(event)
+	}
+}
@@ -0,0 +1,371 @@
+package ruleset
+
+import (
+	"errors"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type status string
+
+type item struct {
+	Quantity int
+}
+
+type account struct {
+	Partner *string
+	Code    *int
+	Active  *bool
+}
+
+type order struct {
+	Status  string
+	Partner string
+	Total   float64
+	Items   []item
+}
+
+func matchedIDs(matched []MatchedRule) []string {
+	var ids []string
+	for _, rule := range matched {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func TestLoadFile(t *testing.T) {
+	for _, path := range []string{"testdata/routing.yaml", "testdata/routing.json"} {
+		t.Run(path, func(t *testing.T) {
+			rules, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+			if len(rules.Rules()) != 3 {
+				t.Fatalf("LoadFile() loaded %d rules, want 3", len(rules.Rules()))
+			}
+			rule, ok := rules.Rule("high-value")
+			if !ok {
+				t.Fatalf("Rule(high-value) not found")
+			}
+			if rule.Version != "3" || rule.Description == "" || !reflect.DeepEqual(rule.Tags, []string{"review", "finance"}) || rule.Metadata["queue"] != "manual-review" {
+				t.Errorf("Rule(high-value) = %+v", rule)
+			}
+			if bulk, _ := rules.Rule("bulk"); bulk.IsEnabled() {
+				t.Errorf("Rule(bulk).IsEnabled() = true, want false")
+			}
+			if partner, _ := rules.Rule("bank-partner"); structgen.Print(*partner.Condition) != `Partner in (bca, bni) && !Status = cancelled` {
+				t.Errorf("Rule(bank-partner).Condition = %v", structgen.Print(*partner.Condition))
+			}
+
+			tests := []struct {
+				name  string
+				order order
+				want  []string
+			}{
+				{
+					name:  "both rules",
+					order: order{Status: "paid", Partner: "bca", Total: 2000000, Items: []item{{Quantity: 500}}},
+					want:  []string{"high-value", "bank-partner"},
+				},
+				{
+					name:  "partner only",
+					order: order{Status: "pending", Partner: "bni", Total: 2000000},
+					want:  []string{"bank-partner"},
+				},
+				{
+					name:  "none",
+					order: order{Status: "cancelled", Partner: "bca", Total: 10},
+				},
+			}
+			for _, tt := range tests {
+				if got := matchedIDs(rules.Evaluate(tt.order)); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Evaluate(%s) = %v, want %v", tt.name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadYAML_RuleErrors(t *testing.T) {
+	data := []byte(`
+rules:
+  - id: ok
+    query: Status = paid
+  - query: Status = paid
+  - id: ok
+    query: Total > 1
+  - id: broken
+    query: (Status = paid
+  - id: empty
+  - id: both
+    query: Status = paid
+    condition:
+      attribute: {name: Status, operator: "=", value: paid}
+`)
+	rules, err := LoadYAML(data)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("LoadYAML() error = %v, want Errors", err)
+	}
+	var gotIndexes []int
+	for _, ruleErr := range errs {
+		gotIndexes = append(gotIndexes, ruleErr.Index)
+	}
+	if !reflect.DeepEqual(gotIndexes, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("LoadYAML() error indexes = %v, want [1 2 3 4 5]\n%v", gotIndexes, err)
+	}
+	var parseErr *structgen.ParseError
+	if !errors.As(errs[2], &parseErr) {
+		t.Errorf("LoadYAML() rule error = %v, want *structgen.ParseError", errs[2])
+	}
+	if len(rules.Rules()) != 1 || rules.Rules()[0].ID != "ok" {
+		t.Errorf("LoadYAML() rules = %+v, want only ok", rules.Rules())
+	}
+
+	if _, err := LoadYAML([]byte("rules: [")); err == nil {
+		t.Errorf("LoadYAML() error = nil, want syntax error")
+	}
+	if _, err := LoadFile("testdata/routing.txt"); err == nil {
+		t.Errorf("LoadFile() error = nil, want error")
+	}
+}
+
+func TestLoadYAML_ConditionErrors(t *testing.T) {
+	data := []byte(`
+rules:
+  - id: ok
+    condition:
+      conditions:
+        - attribute: {name: Status, operator: "=", value: paid, type: alphanumeric}
+        - operator: AND
+          quantifier: any
+          collection: Items
+          conditions:
+            - attribute: {name: Items.Quantity, operator: in, values: ["1", "2"], type: numeric}
+  - id: operator
+    condition:
+      attribute: {name: Status, operator: "==", value: paid}
+  - id: name
+    condition:
+      attribute: {operator: "=", value: paid}
+  - id: type
+    condition:
+      attribute: {name: Total, operator: ">", value: ten, type: numeric}
+  - id: logical operator
+    condition:
+      conditions:
+        - attribute: {name: Status, operator: "=", value: paid}
+        - operator: XOR
+          attribute: {name: Total, operator: ">", value: "1", type: numeric}
+  - id: quantifier
+    condition:
+      quantifier: some
+      collection: Items
+      conditions:
+        - attribute: {name: Items.Quantity, operator: "=", value: "1"}
+  - id: collection
+    condition:
+      quantifier: any
+      conditions:
+        - attribute: {name: Items.Quantity, operator: "=", value: "1"}
+  - id: values
+    condition:
+      attribute: {name: Status, operator: in}
+  - id: function
+    condition:
+      attribute: {name: Items, function: count, operator: "=", value: "1", type: numeric}
+`)
+	rules, err := LoadYAML(data)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("LoadYAML() error = %v, want Errors", err)
+	}
+	var gotIDs []string
+	for _, ruleErr := range errs {
+		gotIDs = append(gotIDs, ruleErr.ID)
+	}
+	wantIDs := []string{"operator", "name", "type", "logical operator", "quantifier", "collection", "values", "function"}
+	if !reflect.DeepEqual(gotIDs, wantIDs) {
+		t.Errorf("LoadYAML() error ids = %v, want %v\n%v", gotIDs, wantIDs, err)
+	}
+	if len(rules.Rules()) != 1 || rules.Rules()[0].ID != "ok" {
+		t.Errorf("LoadYAML() rules = %+v, want only ok", rules.Rules())
+	}
+}
+
+func TestRuleSet_EvaluateE(t *testing.T) {
+	rules, err := New([]*Rule{
+		{ID: "status", Query: `status = paid`},
+		{ID: "total", Query: `total > 10`},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := rules.EvaluateE(nil); err == nil {
+		t.Errorf("EvaluateE(nil) error = nil, want rule errors")
+	}
+
+	rules.SetNameTag("json")
+	data := struct {
+		Status string  `json:"status"`
+		Total  float64 `json:"total"`
+	}{Status: "paid", Total: 5}
+	matched, err := rules.EvaluateE(data)
+	if err != nil || !reflect.DeepEqual(matchedIDs(matched), []string{"status"}) {
+		t.Errorf("EvaluateE() = %v, %v, want [status]", matchedIDs(matched), err)
+	}
+}
+
+func TestRuleSet_Match(t *testing.T) {
+	queries := []string{
+		`Status = paid`,
+		`Status = paid && Total >= 100`,
+		`Partner in (bca, bni) && (Status = paid && Total < 50)`,
+		`Status = paid || Partner = bca`,
+		`!Status = paid && Partner = bri`,
+		`Status in (paid, refunded) && any(Items, Items.Quantity = 3)`,
+		`Total = 75.5`,
+		`Total in (10, 20.0) && Status = pending`,
+		`Total > 1000`,
+		`Status = PAID`,
+		`len(Items) = 2 && Partner = bca`,
+		`Status = Partner`,
+		`Status = $Partner && Total = 10`,
+	}
+	var definitions []*Rule
+	for i, query := range queries {
+		definitions = append(definitions, &Rule{ID: strconv.Itoa(i), Query: query})
+	}
+	disabled := false
+	definitions = append(definitions, &Rule{ID: "disabled", Query: `Status = paid`, Enabled: &disabled})
+	rules, err := New(definitions)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := []interface{}{
+		order{Status: "paid", Partner: "bca", Total: 10, Items: []item{{Quantity: 3}, {Quantity: 1}}},
+		&order{Status: "paid", Partner: "bni", Total: 20},
+		order{Status: "pending", Partner: "bri", Total: 20},
+		order{Status: "refunded", Partner: "bri", Total: 75.5, Items: []item{{Quantity: 3}}},
+		order{Status: "PAID", Partner: "PAID", Total: 5000},
+		order{},
+		map[string]interface{}{"Status": "paid", "Partner": "bca", "Total": 10},
+		map[string]interface{}{"Status": "pending", "Total": 20.0},
+		map[string]interface{}{"Status": nil, "Partner": "bri"},
+		map[string]interface{}{"Items": []interface{}{map[string]interface{}{"Quantity": 3}}, "Status": "refunded"},
+		map[string]interface{}{"Status": status("paid"), "Partner": "bca", "Total": uint16(10)},
+		map[string]interface{}{"Status": "refunded", "Total": float32(75.5)},
+	}
+	for i, event := range events {
+		want := matchedIDs(rules.Evaluate(event))
+		got, err := rules.MatchE(event)
+		if err != nil {
+			t.Errorf("MatchE(events[%d]) error = %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MatchE(events[%d]) = %v, want %v", i, got, want)
+		}
+	}
+
+	if got := rules.Match(events[0]); !reflect.DeepEqual(got, []string{"0", "2", "3", "5", "10"}) {
+		t.Errorf("Match() = %v, want [0 2 3 5 10]", got)
+	}
+	if _, err := rules.MatchE(nil); err == nil {
+		t.Errorf("MatchE(nil) error = nil, want rule errors")
+	}
+	if _, err := rules.MatchE(map[string]interface{}{}); err == nil {
+		t.Errorf("MatchE(empty map) error = nil, want rule errors")
+	}
+}
+
+func TestRuleSet_MatchSettings(t *testing.T) {
+	rules, err := New([]*Rule{
+		{ID: "status", Query: `Event.status = paid`},
+		{ID: "partner", Query: `Event.partner in (bca, bni)`},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	data := struct {
+		Status  string `json:"status"`
+		Partner string `json:"partner"`
+	}{Status: "paid", Partner: "bni"}
+	if got := rules.Match(data); got != nil {
+		t.Errorf("Match() = %v, want none", got)
+	}
+	rules.SetNameTag("json").SetRemovePrefix(true)
+	if got := rules.Match(data); !reflect.DeepEqual(got, []string{"status", "partner"}) {
+		t.Errorf("Match() = %v, want [status partner]", got)
+	}
+}
+
+func TestRuleSet_MatchNullPolicy(t *testing.T) {
+	rules, err := New([]*Rule{
+		{ID: "code", Query: `Code = 0`},
+		{ID: "active", Query: `Active = false && Partner != bni`},
+		{ID: "partner", Query: `Partner = bca`},
+		{ID: "codes", Query: `Code in (1, 2)`},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events := []interface{}{
+		account{},
+		map[string]interface{}{"Code": nil, "Active": nil},
+		map[string]interface{}{"Other": 1},
+	}
+	policies := []nullpolicies.NullPolicy{nullpolicies.False, nullpolicies.ZeroValue, nullpolicies.Unknown, nullpolicies.Error}
+	for _, policy := range policies {
+		rules.SetNullPolicy(policy)
+		for i, event := range events {
+			evaluated, evaluateErr := rules.EvaluateE(event)
+			got, err := rules.MatchE(event)
+			if want := matchedIDs(evaluated); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: MatchE(events[%d]) = %v, want %v", policy, i, got, want)
+			}
+			var errs, wantErrs Errors
+			errors.As(err, &errs)
+			errors.As(evaluateErr, &wantErrs)
+			if len(errs) != len(wantErrs) {
+				t.Errorf("%s: MatchE(events[%d]) error = %v, want %v", policy, i, err, evaluateErr)
+			}
+		}
+	}
+
+	rules.SetNullPolicy(nullpolicies.ZeroValue)
+	if got := rules.Match(account{}); !reflect.DeepEqual(got, []string{"code", "active"}) {
+		t.Errorf("Match() = %v, want [code active]", got)
+	}
+}
+
+func TestRuleSet_MatchNumericMode(t *testing.T) {
+	rules, err := New([]*Rule{
+		{ID: "amount", Query: `Amount = 1500.250`},
+		{ID: "amounts", Query: `Amount in (0.1, 2/3)`},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events := []interface{}{
+		map[string]interface{}{"Amount": "1500.25"},
+		map[string]interface{}{"Amount": 1500.25},
+		map[string]interface{}{"Amount": float32(0.1)},
+		map[string]interface{}{"Amount": "0.10"},
+	}
+	wants := [][]string{{"amount"}, {"amount"}, {"amounts"}, {"amounts"}}
+	rules.SetNumericMode(numericmodes.Decimal)
+	for i, event := range events {
+		got, err := rules.MatchE(event)
+		if err != nil || !reflect.DeepEqual(got, wants[i]) {
+			t.Errorf("MatchE(events[%d]) = %v, %v, want %v", i, got, err, wants[i])
+		}
+		if want := matchedIDs(rules.Evaluate(event)); !reflect.DeepEqual(got, want) {
+			t.Errorf("MatchE(events[%d]) = %v, Evaluate() = %v", i, got, want)
+		}
+	}
+}
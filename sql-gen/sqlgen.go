@@ -0,0 +1,223 @@
+package sqlgen
+
+import (
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/placeholder-styles"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/sql-dialects"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// likeEscape escapes the wildcards of the LIKE pattern a contains comparison
+// is translated to. It isn't a backslash, whose meaning in string literals
+// differs between the dialects.
+const likeEscape = '!'
+
+// identifierPattern matches the attribute names DefaultColumnMapper accepts,
+// one or more dot separated SQL identifiers.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+var comparisonOperators = map[string]string{
+	operators.OperatorEqual:            "=",
+	operators.OperatorNotEqual:         "<>",
+	operators.OperatorLessThan:         "<",
+	operators.OperatorLessThanEqual:    "<=",
+	operators.OperatorGreaterThan:      ">",
+	operators.OperatorGreaterThanEqual: ">=",
+}
+
+var regexOperators = map[sqldialects.SQLDialect]string{
+	sqldialects.Postgres: "~",
+	sqldialects.MySQL:    "REGEXP",
+	sqldialects.SQLite:   "REGEXP",
+}
+
+// ColumnMapper maps the attribute name of a comparison to the column it is
+// written as. The column is put into the SQL text as is, so a mapper must
+// only return trusted names.
+type ColumnMapper func(name string) (column string, err error)
+
+// DefaultColumnMapper uses the attribute name as the column, e.g. `orders.id`,
+// and rejects every name that isn't a plain or qualified identifier.
+func DefaultColumnMapper(name string) (string, error) {
+	if !identifierPattern.MatchString(name) {
+		return "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("column name %q", name))
+	}
+	return name, nil
+}
+
+type SQLGen struct {
+	dialect      sqldialects.SQLDialect
+	placeholder  placeholderstyles.PlaceholderStyle
+	columnMapper ColumnMapper
+}
+
+// NewSQLGen returns a generator for dialect using its usual placeholder
+// style, `$n` for Postgres and `?` otherwise.
+func NewSQLGen(dialect sqldialects.SQLDialect) *SQLGen {
+	placeholder := placeholderstyles.Question
+	if dialect == sqldialects.Postgres {
+		placeholder = placeholderstyles.Dollar
+	}
+	return &SQLGen{
+		dialect:      dialect,
+		placeholder:  placeholder,
+		columnMapper: DefaultColumnMapper,
+	}
+}
+
+// SetPlaceholderStyle sets how the arguments are referenced in the SQL text.
+func (s *SQLGen) SetPlaceholderStyle(style placeholderstyles.PlaceholderStyle) *SQLGen {
+	s.placeholder = style
+	return s
+}
+
+// SetColumnMapper sets the mapping of attribute names to columns.
+func (s *SQLGen) SetColumnMapper(mapper ColumnMapper) *SQLGen {
+	s.columnMapper = mapper
+	return s
+}
+
+// where is the state of one translation, the arguments collected so far.
+type where struct {
+	*SQLGen
+	args []interface{}
+}
+
+// Where translates condition into the body of a WHERE clause and its
+// arguments. Values are only ever passed as arguments, never written into the
+// SQL text. A group mixing AND and OR is parenthesized run by run, so SQL
+// keeps the left to right order of the query. An empty condition gives an
+// empty clause. Quantifiers, functions and exists have no translation and
+// give an error, a column always exists.
+func (s *SQLGen) Where(condition structs.Condition) (clause string, args []interface{}, err error) {
+	if _, ok := regexOperators[s.dialect]; !ok {
+		return "", nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("dialect %q", s.dialect))
+	}
+	switch s.placeholder {
+	case placeholderstyles.Question, placeholderstyles.Dollar:
+	default:
+		return "", nil, fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("placeholder style %q", s.placeholder))
+	}
+	if condition.IsEmpty() {
+		return "", nil, nil
+	}
+	w := &where{SQLGen: s}
+	if condition.Quantifier == "" && len(condition.Conditions) > 0 && !condition.Negate {
+		clause, err = w.sequence(condition.Conditions)
+	} else {
+		clause, err = w.term(&condition)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, w.args, nil
+}
+
+// sequence writes the terms of a group, `a OR b AND c` as `(a OR b) AND c`.
+func (w *where) sequence(conditions []*structs.Condition) (string, error) {
+	operator, terms, err := structs.Fold(conditions, w.term, func(operator string, terms []string) string {
+		return "(" + strings.Join(terms, " "+operator+" ") + ")"
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(terms, " "+operator+" "), nil
+}
+
+func (w *where) term(condition *structs.Condition) (term string, err error) {
+	switch {
+	case condition.Quantifier != "":
+		return "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("quantifier %s", condition.Quantifier))
+	case len(condition.Conditions) > 0:
+		term, err = w.sequence(condition.Conditions)
+		if err == nil && (len(condition.Conditions) > 1 || condition.Negate) {
+			term = "(" + term + ")"
+		}
+	case condition.Attribute != nil:
+		term, err = w.comparison(condition.Attribute)
+		if err == nil && condition.Negate {
+			term = "(" + term + ")"
+		}
+	default:
+		return "", fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "attribute")
+	}
+	if err != nil || !condition.Negate {
+		return term, err
+	}
+	return "NOT " + term, nil
+}
+
+func (w *where) comparison(attribute *structs.Attribute) (string, error) {
+	if attribute.Function != "" {
+		return "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("function %s", attribute.Function))
+	}
+	column, err := w.columnMapper(attribute.Name)
+	if err != nil {
+		return "", err
+	}
+
+	switch attribute.Operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		if len(attribute.Values) == 0 {
+			return "", fmt.Errorf(errormessages.ErrorMessageInvalidParameter, "value list")
+		}
+		placeholders := make([]string, len(attribute.Values))
+		for i, value := range attribute.Values {
+			placeholders[i] = w.bind(structgen.TypedValue(value, attribute.Type))
+		}
+		operator := "IN"
+		if attribute.Operator == operators.OperatorNotIn {
+			operator = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", column, operator, strings.Join(placeholders, ", ")), nil
+	case operators.OperatorContains:
+		return fmt.Sprintf("%s LIKE %s ESCAPE '%c'", column, w.bind("%"+escapeLike(attribute.Value)+"%"), likeEscape), nil
+	case operators.OperatorContainsRegexMatch:
+		return fmt.Sprintf("%s %s %s", column, regexOperators[w.dialect], w.bind(attribute.Value)), nil
+	case operators.OperatorIsNull:
+		return column + " IS NULL", nil
+	case operators.OperatorIsNotNull:
+		return column + " IS NOT NULL", nil
+	}
+
+	operator, ok := comparisonOperators[attribute.Operator]
+	if !ok {
+		return "", fmt.Errorf(errormessages.ErrorMessageUnsupported, fmt.Sprintf("operator %q", attribute.Operator))
+	}
+	if attribute.Type == valuetypes.Field {
+		reference, err := w.columnMapper(attribute.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", column, operator, reference), nil
+	}
+	return fmt.Sprintf("%s %s %s", column, operator, w.bind(structgen.TypedValue(attribute.Value, attribute.Type))), nil
+}
+
+// bind adds value to the arguments and returns its placeholder.
+func (w *where) bind(value interface{}) string {
+	w.args = append(w.args, value)
+	if w.placeholder == placeholderstyles.Dollar {
+		return "$" + strconv.Itoa(len(w.args))
+	}
+	return "?"
+}
+
+// escapeLike escapes the LIKE wildcards in value so it matches literally.
+func escapeLike(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		if char == '%' || char == '_' || char == likeEscape {
+			builder.WriteRune(likeEscape)
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
@@ -0,0 +1,167 @@
+package sqlgen
+
+import (
+	"errors"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/placeholder-styles"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/sql-dialects"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLGen_Where(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		legacyPrecedence bool
+		gen              *SQLGen
+		want             string
+		wantArgs         []interface{}
+		wantErr          bool
+	}{
+		{
+			name:     "Normal case - postgres",
+			query:    `id = 1 && (division = engineering || division = "finance") && amount >= 10.5`,
+			gen:      NewSQLGen(sqldialects.Postgres),
+			want:     `id = $1 AND (division = $2 OR division = $3) AND amount >= $4`,
+			wantArgs: []interface{}{int64(1), "engineering", "finance", 10.5},
+		},
+		{
+			name:     "Normal case - mysql placeholders",
+			query:    `id != 1 && created_at < 2024-01-02T03:04:05Z`,
+			gen:      NewSQLGen(sqldialects.MySQL),
+			want:     `id <> ? AND created_at < ?`,
+			wantArgs: []interface{}{int64(1), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+		{
+			name:     "Normal case - placeholder style override",
+			query:    `id = 1 && name = reza`,
+			gen:      NewSQLGen(sqldialects.SQLite).SetPlaceholderStyle(placeholderstyles.Dollar),
+			want:     `id = $1 AND name = $2`,
+			wantArgs: []interface{}{int64(1), "reza"},
+		},
+		{
+			name:             "Normal case - left to right fold",
+			query:            `a = 1 || b = 2 && c = 3 || d = 4`,
+			legacyPrecedence: true,
+			gen:              NewSQLGen(sqldialects.Postgres),
+			want:             `((a = $1 OR b = $2) AND c = $3) OR d = $4`,
+			wantArgs:         []interface{}{int64(1), int64(2), int64(3), int64(4)},
+		},
+		{
+			name:     "Normal case - and binds tighter than or",
+			query:    `a = 1 || b = 2 && c = 3`,
+			gen:      NewSQLGen(sqldialects.Postgres),
+			want:     `a = $1 OR (b = $2 AND c = $3)`,
+			wantArgs: []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "Normal case - negation",
+			query:    `!status = closed && !(a = 1 || b = 2) && !(c = 3)`,
+			gen:      NewSQLGen(sqldialects.Postgres),
+			want:     `NOT (status = $1) AND NOT (a = $2 OR b = $3) AND NOT (c = $4)`,
+			wantArgs: []interface{}{"closed", int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "Normal case - lists and references",
+			query:    `partner in (bca, "bni") && member_id not in (1, 2) && debit = $orders.total`,
+			gen:      NewSQLGen(sqldialects.Postgres),
+			want:     `partner IN ($1, $2) AND member_id NOT IN ($3, $4) AND debit = orders.total`,
+			wantArgs: []interface{}{"bca", "bni", int64(1), int64(2)},
+		},
+		{
+			name:     "Normal case - contains and regex",
+			query:    `note |= "50%_off!" && name |~ "^re"`,
+			gen:      NewSQLGen(sqldialects.MySQL),
+			want:     `note LIKE ? ESCAPE '!' AND name REGEXP ?`,
+			wantArgs: []interface{}{"%50!%!_off!!%", "^re"},
+		},
+		{
+			name:     "Normal case - postgres regex",
+			query:    `name |~ "^re"`,
+			gen:      NewSQLGen(sqldialects.Postgres),
+			want:     `name ~ $1`,
+			wantArgs: []interface{}{"^re"},
+		},
+		{
+			name:  "Normal case - column mapper",
+			query: `Payload.Status = paid`,
+			gen: NewSQLGen(sqldialects.Postgres).SetColumnMapper(func(name string) (string, error) {
+				return "e." + strings.ToLower(strings.ReplaceAll(name, ".", "_")), nil
+			}),
+			want:     `e.payload_status = $1`,
+			wantArgs: []interface{}{"paid"},
+		},
+		{
+			name:  "Normal case - nulls",
+			query: `sender_type is null || (partner is not null && !(account is null))`,
+			gen:   NewSQLGen(sqldialects.Postgres),
+			want:  `sender_type IS NULL OR (partner IS NOT NULL AND NOT (account IS NULL))`,
+		},
+		{
+			name:  "Normal case - empty condition",
+			query: ``,
+			gen:   NewSQLGen(sqldialects.Postgres),
+			want:  ``,
+		},
+		{
+			name:    "Error case - quantifier",
+			query:   `any(Items, Items.Amount > 1)`,
+			gen:     NewSQLGen(sqldialects.Postgres),
+			wantErr: true,
+		},
+		{
+			name:    "Error case - function",
+			query:   `len(Items) > 1`,
+			gen:     NewSQLGen(sqldialects.Postgres),
+			wantErr: true,
+		},
+		{
+			name:    "Error case - exists",
+			query:   `exists(sender_type)`,
+			gen:     NewSQLGen(sqldialects.Postgres),
+			wantErr: true,
+		},
+		{
+			name:    "Error case - unsafe column name",
+			query:   `"id; DROP TABLE users" = 1`,
+			gen:     NewSQLGen(sqldialects.Postgres),
+			wantErr: true,
+		},
+		{
+			name:    "Error case - unknown dialect",
+			query:   `id = 1`,
+			gen:     NewSQLGen(sqldialects.FromString("oracle")),
+			wantErr: true,
+		},
+		{
+			name:  "Error case - column mapper",
+			query: `id = 1`,
+			gen: NewSQLGen(sqldialects.Postgres).SetColumnMapper(func(name string) (string, error) {
+				return "", errors.New("unknown column")
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := structgen.StructGen{LegacyPrecedence: tt.legacyPrecedence}
+			condition, err := gen.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			got, args, err := tt.gen.Where(condition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Where() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Where() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Where() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
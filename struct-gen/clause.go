@@ -0,0 +1,126 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/sort-directions"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strconv"
+	"strings"
+)
+
+// clauseSeparator starts a clause after the condition of a base query.
+const clauseSeparator = "|"
+
+const (
+	clauseSort     = "sort"
+	clauseLimit    = "limit"
+	clauseOffset   = "offset"
+	clausePage     = "page"
+	clauseFields   = "fields"
+	clauseDistinct = "distinct"
+)
+
+// parseClauses parses the clauses following the condition of a base query:
+//
+//	clauses = { "|" clause }
+//	clause  = "sort" key { "," key } | "limit" count [ "offset" count ]
+//	        | "offset" count | "page" count | "fields" name { "," name }
+//	        | "distinct"
+//	key     = name [ "asc" | "desc" ]
+func (p *parser) parseClauses(base *structs.BaseCondition) error {
+	for {
+		tok := p.peek()
+		if tok == nil {
+			return nil
+		}
+		if !isSymbol(tok, clauseSeparator) {
+			return p.unexpected("'&&', '||', '|' or end of query")
+		}
+		p.next()
+
+		var err error
+		switch {
+		case isKeyword(p.peek(), clauseSort):
+			p.next()
+			err = p.parseSort(&base.Footer)
+		case isKeyword(p.peek(), clauseLimit):
+			p.next()
+			if base.Footer.Limit, err = p.parseCount(); err == nil && isKeyword(p.peek(), clauseOffset) {
+				p.next()
+				base.Footer.Offset, err = p.parseCount()
+			}
+		case isKeyword(p.peek(), clauseOffset):
+			p.next()
+			base.Footer.Offset, err = p.parseCount()
+		case isKeyword(p.peek(), clausePage):
+			p.next()
+			base.Footer.Page, err = p.parseCount()
+		case isKeyword(p.peek(), clauseFields):
+			p.next()
+			base.Fields, err = p.parseNames(base.Fields, "field name")
+		case isKeyword(p.peek(), clauseDistinct):
+			p.next()
+			base.Distinct = true
+		default:
+			err = p.unexpected("sort, limit, offset, page, fields or distinct")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseSort(footer *structs.Footer) error {
+	if footer.Sort == nil {
+		footer.Sort = make(map[string]string)
+	}
+	for {
+		name := p.peek()
+		if !isWord(name) {
+			return p.unexpected("sort field name")
+		}
+		p.next()
+
+		direction := sortdirections.SortDirectionAsc
+		if isKeyword(p.peek(), sortdirections.SortDirectionAsc) || isKeyword(p.peek(), sortdirections.SortDirectionDesc) {
+			direction = strings.ToLower(p.next().Value)
+		}
+		if _, ok := footer.Sort[name.Value]; !ok {
+			footer.SortKeys = append(footer.SortKeys, name.Value)
+		}
+		footer.Sort[name.Value] = direction
+
+		if !isSymbol(p.peek(), ",") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseNames(names []string, expected string) ([]string, error) {
+	for {
+		name := p.peek()
+		if !isWord(name) {
+			return nil, p.unexpected(expected)
+		}
+		p.next()
+		names = append(names, name.Value)
+
+		if !isSymbol(p.peek(), ",") {
+			return names, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseCount() (int, error) {
+	tok := p.peek()
+	if tok == nil || tok.IsAlphanumeric {
+		return 0, p.unexpected("non-negative integer")
+	}
+	count, err := strconv.Atoi(tok.Value)
+	if err != nil || count < 0 {
+		return 0, p.unexpected("non-negative integer")
+	}
+	p.next()
+	return count, nil
+}
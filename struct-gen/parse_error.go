@@ -0,0 +1,44 @@
+package structgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrParse is the sentinel every *ParseError is, see errors.Is.
+var ErrParse = errors.New("parse error")
+
+// ParseError reports a syntax error in a query. Offset is the byte offset of
+// the offending token, Line and Column are 1-based and Token is empty when the
+// query ended unexpectedly.
+type ParseError struct {
+	Offset   int
+	Line     int
+	Column   int
+	Token    string
+	Expected string
+}
+
+func newParseError(query string, offset int, token, expected string) *ParseError {
+	lineStart := strings.LastIndexByte(query[:offset], '\n') + 1
+	return &ParseError{
+		Offset:   offset,
+		Line:     strings.Count(query[:offset], "\n") + 1,
+		Column:   utf8.RuneCountInString(query[lineStart:offset]) + 1,
+		Token:    token,
+		Expected: expected,
+	}
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("syntax error at line %d, column %d: unexpected end of query, expected %s", e.Line, e.Column, e.Expected)
+	}
+	return fmt.Sprintf("syntax error at line %d, column %d: unexpected %q, expected %s", e.Line, e.Column, e.Token, e.Expected)
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParse
+}
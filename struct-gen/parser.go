@@ -0,0 +1,384 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/functions"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strings"
+)
+
+// The keywords of `not in` and `is [not] null` comparisons.
+const (
+	keywordIs   = "is"
+	keywordNot  = "not"
+	keywordNull = "null"
+)
+
+// parser checks the token stream against the query grammar while it builds
+// the condition tree. `&&` binds tighter than `||` unless isLegacyPrecedence
+// is set:
+//
+//	sequence   = term { logical term }
+//	term       = "!" term | "(" sequence ")" | quantifier | comparison
+//	quantifier = ( "any" | "all" | "none" ) "(" attribute "," sequence ")"
+//	comparison = operand operator value | operand [ "not" ] "in" list
+//	           | attribute "is" [ "not" ] "null" | "exists" "(" attribute ")"
+//	operand    = attribute | "len" "(" attribute ")"
+//	value      = literal | "$" attribute
+//	list       = "(" value { "," value } ")"
+type parser struct {
+	query              string
+	tokens             []*structs.TokenAttribute
+	offsets            []int
+	pos                int
+	attributeNames     map[string]interface{}
+	isLegacyPrecedence bool
+}
+
+func (p *parser) peek() *structs.TokenAttribute {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() *structs.TokenAttribute {
+	tok := p.peek()
+	if tok != nil {
+		p.pos++
+	}
+	return tok
+}
+
+// unexpected builds a parse error for the current token.
+func (p *parser) unexpected(expected string) error {
+	if p.pos >= len(p.tokens) {
+		return newParseError(p.query, len(p.query), "", expected)
+	}
+	return newParseError(p.query, p.offsets[p.pos], p.tokens[p.pos].Value, expected)
+}
+
+// parseSequence stops at the first token that doesn't continue the sequence,
+// the caller decides whether that token may follow it.
+func (p *parser) parseSequence(condition *structs.Condition) error {
+	operator := ""
+	var terms []*structs.Condition
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return err
+		}
+		term.Operator = operator
+		terms = append(terms, term)
+
+		tok := p.peek()
+		if tok == nil || tok.IsAlphanumeric {
+			break
+		}
+		val, ok := logicalOperatorMap[tok.Value]
+		if !ok {
+			break
+		}
+		operator = val
+		p.next()
+	}
+	if p.isLegacyPrecedence {
+		condition.Conditions = append(condition.Conditions, terms...)
+	} else {
+		condition.Conditions = append(condition.Conditions, groupAndRuns(terms)...)
+	}
+	return nil
+}
+
+// groupAndRuns groups the terms so that folding them left to right, the way
+// sequences are evaluated, gives `&&` precedence over `||`. Only the runs of
+// and-ed terms after an `||` need a group of their own: `a && b || c && d`
+// becomes `a && b || (c && d)`.
+func groupAndRuns(terms []*structs.Condition) []*structs.Condition {
+	i := 1
+	for i < len(terms) && terms[i].Operator != logicaloperators.LogicalOperatorOr {
+		i++
+	}
+	result := terms[:i:i]
+	for i < len(terms) {
+		j := i + 1
+		for j < len(terms) && terms[j].Operator != logicaloperators.LogicalOperatorOr {
+			j++
+		}
+		if j-i == 1 {
+			result = append(result, terms[i])
+		} else {
+			run := append([]*structs.Condition(nil), terms[i:j]...)
+			result = append(result, &structs.Condition{
+				Operator:   logicaloperators.LogicalOperatorOr,
+				Conditions: run,
+			})
+			run[0].Operator = ""
+		}
+		i = j
+	}
+	return result
+}
+
+func (p *parser) parseTerm() (*structs.Condition, error) {
+	tok := p.peek()
+	if isSymbol(tok, logicaloperators.LogicalOperatorNotSyntax) {
+		p.next()
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		term.Negate = !term.Negate
+		return term, nil
+	}
+	if isQuantifier(tok) && p.pos+1 < len(p.tokens) && isSymbol(p.tokens[p.pos+1], "(") {
+		return p.parseQuantifier()
+	}
+	if isSymbol(tok, "(") {
+		p.next()
+		var group structs.Condition
+		if err := p.parseSequence(&group); err != nil {
+			return nil, err
+		}
+		if !isSymbol(p.peek(), ")") {
+			return nil, p.unexpected("'&&', '||' or ')'")
+		}
+		p.next()
+		return &group, nil
+	}
+	return p.parseComparison()
+}
+
+// parseQuantifier parses `any(collection, sequence)` and its all / none
+// variants, the sequence is evaluated against every element of the collection.
+func (p *parser) parseQuantifier() (*structs.Condition, error) {
+	condition := structs.Condition{
+		Quantifier: strings.ToLower(p.next().Value),
+	}
+	p.next()
+
+	collection := p.peek()
+	if !isWord(collection) {
+		return nil, p.unexpected("collection attribute name")
+	}
+	p.next()
+	if !isSymbol(p.peek(), ",") {
+		return nil, p.unexpected("','")
+	}
+	p.next()
+
+	condition.Collection = collection.Value
+	if err := p.parseSequence(&condition); err != nil {
+		return nil, err
+	}
+	if !isSymbol(p.peek(), ")") {
+		return nil, p.unexpected("'&&', '||' or ')'")
+	}
+	p.next()
+	p.attributeNames[collection.Value] = nil
+	return &condition, nil
+}
+
+func (p *parser) parseComparison() (*structs.Condition, error) {
+	if isKeyword(p.peek(), operators.OperatorExists) && p.pos+1 < len(p.tokens) && isSymbol(p.tokens[p.pos+1], "(") {
+		p.pos += 2
+		return p.parseExists()
+	}
+	function := ""
+	if isKeyword(p.peek(), functions.FunctionLen) && p.pos+1 < len(p.tokens) && isSymbol(p.tokens[p.pos+1], "(") {
+		function = functions.FunctionLen
+		p.pos += 2
+	}
+	name := p.peek()
+	if !isWord(name) {
+		if function != "" {
+			return nil, p.unexpected("attribute name")
+		}
+		return nil, p.unexpected("attribute name, '!' or '('")
+	}
+	p.next()
+	if function != "" {
+		if !isSymbol(p.peek(), ")") {
+			return nil, p.unexpected("')'")
+		}
+		p.next()
+	}
+
+	if isKeyword(p.peek(), operators.OperatorIn) {
+		p.next()
+		return p.parseList(name, function, operators.OperatorIn)
+	}
+	if isKeyword(p.peek(), keywordNot) && p.pos+1 < len(p.tokens) && isKeyword(p.tokens[p.pos+1], operators.OperatorIn) {
+		p.pos += 2
+		return p.parseList(name, function, operators.OperatorNotIn)
+	}
+	if function == "" && isKeyword(p.peek(), keywordIs) {
+		p.next()
+		return p.parseIsNull(name)
+	}
+
+	operator := p.peek()
+	if !isOperator(operator) {
+		return nil, p.unexpected("comparison operator")
+	}
+	p.next()
+
+	value := p.peek()
+	if isOperator(value) && p.offsets[p.pos] == p.offsets[p.pos-1]+len(operator.Value) {
+		return nil, newParseError(p.query, p.offsets[p.pos-1], operator.Value+value.Value, "comparison operator")
+	}
+	if !isWord(value) {
+		return nil, p.unexpected("value")
+	}
+	p.next()
+
+	attribute := &structs.Attribute{
+		Name:     name.Value,
+		Function: function,
+		Operator: operator.Value,
+		Value:    value.Value,
+	}
+	if !value.IsAlphanumeric {
+		attribute.Type = ValueType(value.Value)
+		if reference := strings.TrimPrefix(value.Value, fieldReferencePrefix); reference != value.Value && reference != "" {
+			attribute.Value = reference
+			attribute.Type = valuetypes.Field
+			p.attributeNames[reference] = nil
+		}
+	}
+	p.attributeNames[name.Value] = nil
+	return &structs.Condition{
+		Attribute: attribute,
+	}, nil
+}
+
+// parseExists parses the attribute of `exists(attribute)`.
+func (p *parser) parseExists() (*structs.Condition, error) {
+	name := p.peek()
+	if !isWord(name) {
+		return nil, p.unexpected("attribute name")
+	}
+	p.next()
+	if !isSymbol(p.peek(), ")") {
+		return nil, p.unexpected("')'")
+	}
+	p.next()
+	p.attributeNames[name.Value] = nil
+	return &structs.Condition{
+		Attribute: &structs.Attribute{
+			Name:     name.Value,
+			Operator: operators.OperatorExists,
+		},
+	}, nil
+}
+
+// parseIsNull parses what follows the `is` of `attribute is [not] null`.
+func (p *parser) parseIsNull(name *structs.TokenAttribute) (*structs.Condition, error) {
+	operator := operators.OperatorIsNull
+	expected := "'null' or 'not null'"
+	if isKeyword(p.peek(), keywordNot) {
+		p.next()
+		operator, expected = operators.OperatorIsNotNull, "'null'"
+	}
+	if !isKeyword(p.peek(), keywordNull) {
+		return nil, p.unexpected(expected)
+	}
+	p.next()
+	p.attributeNames[name.Value] = nil
+	return &structs.Condition{
+		Attribute: &structs.Attribute{
+			Name:     name.Value,
+			Operator: operator,
+		},
+	}, nil
+}
+
+// parseList parses the parenthesised value list of an in / not in comparison.
+// The attribute only gets a type when every value is unquoted and of the same
+// type.
+func (p *parser) parseList(name *structs.TokenAttribute, function, operator string) (*structs.Condition, error) {
+	if !isSymbol(p.peek(), "(") {
+		return nil, p.unexpected("'('")
+	}
+	p.next()
+
+	attribute := &structs.Attribute{
+		Name:     name.Value,
+		Function: function,
+		Operator: operator,
+	}
+	for {
+		value := p.peek()
+		if !isWord(value) {
+			return nil, p.unexpected("value")
+		}
+		p.next()
+
+		valueType := valuetypes.ValueType("")
+		if !value.IsAlphanumeric {
+			valueType = ValueType(value.Value)
+		}
+		if len(attribute.Values) == 0 {
+			attribute.Type = valueType
+		} else if attribute.Type != valueType {
+			attribute.Type = ""
+		}
+		attribute.Values = append(attribute.Values, value.Value)
+
+		if isSymbol(p.peek(), ")") {
+			p.next()
+			break
+		}
+		if !isSymbol(p.peek(), ",") {
+			return nil, p.unexpected("',' or ')'")
+		}
+		p.next()
+	}
+	p.attributeNames[name.Value] = nil
+	return &structs.Condition{
+		Attribute: attribute,
+	}, nil
+}
+
+// isSymbol reports whether tok is the unquoted symbol value.
+func isSymbol(tok *structs.TokenAttribute, value string) bool {
+	return tok != nil && !tok.IsAlphanumeric && tok.Value == value
+}
+
+func isOperator(tok *structs.TokenAttribute) bool {
+	if tok == nil || tok.IsAlphanumeric {
+		return false
+	}
+	_, ok := operatorMap[tok.Value]
+	return ok
+}
+
+// isWord reports whether tok can be used as an attribute name or a value.
+func isWord(tok *structs.TokenAttribute) bool {
+	if tok == nil {
+		return false
+	}
+	if tok.IsAlphanumeric {
+		return true
+	}
+	if _, ok := logicalOperatorMap[tok.Value]; ok {
+		return false
+	}
+	switch tok.Value {
+	case "(", ")", ",", logicaloperators.LogicalOperatorNotSyntax:
+		return false
+	}
+	return !isOperator(tok)
+}
+
+func isQuantifier(tok *structs.TokenAttribute) bool {
+	return isKeyword(tok, quantifiers.QuantifierAny) || isKeyword(tok, quantifiers.QuantifierAll) || isKeyword(tok, quantifiers.QuantifierNone)
+}
+
+// isKeyword reports whether tok is the unquoted keyword, ignoring case.
+func isKeyword(tok *structs.TokenAttribute, keyword string) bool {
+	return tok != nil && !tok.IsAlphanumeric && strings.EqualFold(tok.Value, keyword)
+}
@@ -0,0 +1,221 @@
+package structgen
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strings"
+)
+
+// printIndent is the indentation of one nesting level in pretty mode.
+const printIndent = "  "
+
+// reservedChars are the characters that end an unquoted word or change its
+// meaning, a name or value holding one of them has to be quoted.
+const reservedChars = " \t\r\n'\"()=<>!|&~"
+
+// separator splits list values and the collection of a quantifier from its
+// sequence, it is part of the word anywhere else.
+const separator = ","
+
+type printer struct {
+	builder strings.Builder
+	pretty  bool
+	depth   int
+}
+
+// Print formats condition as canonical query text: single spaces around the
+// operators, `&&` / `||` between terms and parentheses around every group.
+// Parsing the result with GenerateCondition gives back condition, except that
+// sequences with an `&&` after an `||` come back with the parenthesised terms
+// grouped.
+func Print(condition structs.Condition) string {
+	p := &printer{}
+	p.printRoot(&condition)
+	return p.builder.String()
+}
+
+// PrintPretty formats condition like Print, but puts every term on its own
+// line and indents the content of groups and quantifiers.
+func PrintPretty(condition structs.Condition) string {
+	p := &printer{pretty: true}
+	p.printRoot(&condition)
+	return p.builder.String()
+}
+
+// printRoot prints the top level sequence of a generated condition, any other
+// node is printed as a single term.
+func (p *printer) printRoot(condition *structs.Condition) {
+	switch {
+	case condition.IsEmpty():
+	case condition.Quantifier == "" && len(condition.Conditions) > 0 && !condition.Negate:
+		p.printSequence(condition.Conditions)
+	default:
+		p.printTerm(condition)
+	}
+}
+
+// printSequence prints the terms of a sequence. Sequences are folded left to
+// right while `&&` binds tighter than `||` in a query, so the terms before an
+// `&&` that follows an `||` are put in parentheses: the sequence `a || b && c`
+// is printed as `(a || b) && c`.
+func (p *printer) printSequence(conditions []*structs.Condition) {
+	closes := make([]bool, len(conditions))
+	hasOr := false
+	for i, condition := range conditions[1:] {
+		if condition.Operator == logicaloperators.LogicalOperatorOr {
+			hasOr = true
+		} else if hasOr {
+			closes[i+1] = true
+			hasOr = false
+			p.builder.WriteByte('(')
+		}
+	}
+	for i, condition := range conditions {
+		if closes[i] {
+			p.builder.WriteByte(')')
+		}
+		if i > 0 {
+			if p.pretty {
+				p.newLine()
+			} else {
+				p.builder.WriteByte(' ')
+			}
+			if condition.Operator == logicaloperators.LogicalOperatorOr {
+				p.builder.WriteString(logicaloperators.LogicalOperatorOrSyntax)
+			} else {
+				p.builder.WriteString(logicaloperators.LogicalOperatorAndSyntax)
+			}
+			p.builder.WriteByte(' ')
+		}
+		p.printTerm(condition)
+	}
+}
+
+func (p *printer) printTerm(condition *structs.Condition) {
+	if condition.Negate {
+		p.builder.WriteString(logicaloperators.LogicalOperatorNotSyntax)
+	}
+	switch {
+	case condition.Quantifier != "":
+		p.builder.WriteString(condition.Quantifier)
+		p.builder.WriteByte('(')
+		p.builder.WriteString(quoteName(condition.Collection))
+		p.builder.WriteByte(',')
+		if !p.pretty {
+			p.builder.WriteByte(' ')
+		}
+		p.printGroup(condition.Conditions)
+	case len(condition.Conditions) > 0:
+		p.builder.WriteByte('(')
+		p.printGroup(condition.Conditions)
+	case condition.Attribute != nil:
+		p.printComparison(condition.Attribute)
+	}
+}
+
+// printGroup prints the sequence of a group or quantifier after its opening
+// parenthesis, and the closing one.
+func (p *printer) printGroup(conditions []*structs.Condition) {
+	if !p.pretty {
+		p.printSequence(conditions)
+		p.builder.WriteByte(')')
+		return
+	}
+	p.depth++
+	p.newLine()
+	p.printSequence(conditions)
+	p.depth--
+	p.newLine()
+	p.builder.WriteByte(')')
+}
+
+func (p *printer) printComparison(attribute *structs.Attribute) {
+	name := quoteName(attribute.Name)
+	switch attribute.Operator {
+	case operators.OperatorExists:
+		p.builder.WriteString(operators.OperatorExists + "(" + name + ")")
+		return
+	case operators.OperatorIsNull, operators.OperatorIsNotNull:
+		p.builder.WriteString(name + " " + attribute.Operator)
+		return
+	}
+	if attribute.Function != "" {
+		name = attribute.Function + "(" + name + ")"
+	}
+	p.builder.WriteString(name)
+	p.builder.WriteByte(' ')
+	p.builder.WriteString(attribute.Operator)
+	p.builder.WriteByte(' ')
+
+	switch attribute.Operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		values := make([]string, len(attribute.Values))
+		for i, value := range attribute.Values {
+			if strings.Contains(value, separator) {
+				values[i] = quote(value)
+				continue
+			}
+			values[i] = quoteValue(value, attribute.Type)
+		}
+		p.builder.WriteByte('(')
+		p.builder.WriteString(strings.Join(values, ", "))
+		p.builder.WriteByte(')')
+	default:
+		if attribute.Type == valuetypes.Field {
+			p.builder.WriteString(fieldReferencePrefix + attribute.Value)
+			return
+		}
+		p.builder.WriteString(quoteValue(attribute.Value, attribute.Type))
+	}
+}
+
+func (p *printer) newLine() {
+	p.builder.WriteByte('\n')
+	p.builder.WriteString(strings.Repeat(printIndent, p.depth))
+}
+
+// quoteName quotes an attribute name that wouldn't be read back as one word.
+func quoteName(name string) string {
+	if name == "" || strings.ContainsAny(name, reservedChars) || strings.Contains(name, separator) {
+		return quote(name)
+	}
+	return name
+}
+
+// quoteValue quotes a value unless it is read back unquoted as the same value
+// of valueType. Quoted values have no type, so a value without one is always
+// quoted. A leading ! is read back as part of the value.
+func quoteValue(value string, valueType valuetypes.ValueType) string {
+	word := strings.TrimPrefix(value, logicaloperators.LogicalOperatorNotSyntax)
+	if valueType == "" || word == "" || strings.ContainsAny(word, reservedChars) || len(value) > len(fieldReferencePrefix) && strings.HasPrefix(value, fieldReferencePrefix) {
+		return quote(value)
+	}
+	return value
+}
+
+// quote wraps value in double quotes the way the tokenizer reads them back:
+// the backslashes right before a quote or at the end of value are doubled and
+// the quotes in it are escaped, any other backslash is written as it is.
+func quote(value string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	backslashes := 0
+	for _, char := range value {
+		switch char {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			builder.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+		default:
+			builder.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		builder.WriteRune(char)
+	}
+	builder.WriteString(strings.Repeat(`\`, 2*backslashes))
+	builder.WriteByte('"')
+	return builder.String()
+}
@@ -7,11 +7,22 @@ import (
 	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
 	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
 	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// fieldReferencePrefix marks an unquoted value as the name of another field,
+// e.g. `Debit = $TotalAmount`.
+const fieldReferencePrefix = "$"
+
 type StructGen struct {
 	AttributeNames map[string]interface{}
+	// LegacyPrecedence parses `&&` and `||` left to right with the same
+	// precedence, the way queries were parsed before `&&` bound tighter:
+	// `a || b && c` is `(a || b) && c`. It is meant for stored queries that
+	// rely on that order.
+	LegacyPrecedence bool
 }
 
 var (
@@ -33,161 +44,315 @@ var (
 )
 
 func (s *StructGen) GenerateCondition(query string) (structs.Condition, error) {
-	tokenAttributes := getTokenAttributes(query)
+	tokenAttributes, offsets, err := tokenize(query)
+	if err != nil {
+		return structs.Condition{}, err
+	}
 	if len(tokenAttributes) == 0 {
 		return structs.Condition{Attribute: &structs.Attribute{}}, nil
 	}
 	s.AttributeNames = make(map[string]interface{})
-	_, condition := buildCondition(structs.Condition{}, tokenAttributes, s.AttributeNames)
+	return buildCondition(query, tokenAttributes, offsets, s.AttributeNames, s.LegacyPrecedence)
+}
+
+// GenerateBaseCondition parses a query followed by optional clauses, e.g.
+// `status=active | sort createdAt desc, id | limit 20 offset 40 | fields id,amount | distinct`.
+func (s *StructGen) GenerateBaseCondition(query string) (structs.BaseCondition, error) {
+	tokenAttributes, offsets, err := tokenize(query)
+	if err != nil {
+		return structs.BaseCondition{}, err
+	}
+	s.AttributeNames = make(map[string]interface{})
+	p := &parser{
+		query:              query,
+		tokens:             tokenAttributes,
+		offsets:            offsets,
+		attributeNames:     s.AttributeNames,
+		isLegacyPrecedence: s.LegacyPrecedence,
+	}
+	var base structs.BaseCondition
+	if tok := p.peek(); tok != nil && !isSymbol(tok, clauseSeparator) {
+		var condition structs.Condition
+		if err := p.parseSequence(&condition); err != nil {
+			return structs.BaseCondition{}, err
+		}
+		base.Conditions = condition.Conditions
+	}
+	if err := p.parseClauses(&base); err != nil {
+		return structs.BaseCondition{}, err
+	}
+	return base, nil
+}
+
+func buildCondition(query string, attrs []*structs.TokenAttribute, offsets []int, attributeNames map[string]interface{}, isLegacyPrecedence bool) (structs.Condition, error) {
+	p := &parser{
+		query:              query,
+		tokens:             attrs,
+		offsets:            offsets,
+		attributeNames:     attributeNames,
+		isLegacyPrecedence: isLegacyPrecedence,
+	}
+	var condition structs.Condition
+	if err := p.parseSequence(&condition); err != nil {
+		return structs.Condition{}, err
+	}
+	if tok := p.peek(); tok != nil {
+		return structs.Condition{}, p.unexpected("'&&', '||' or end of query")
+	}
 	return condition, nil
 }
 
-func buildCondition(condition structs.Condition, attrs []*structs.TokenAttribute, attributeNames map[string]interface{}) (int, structs.Condition) {
-	var (
-		conditionItem *structs.Condition
-		lastPos       int
-		operator      string
-	)
-	for i := 0; i < len(attrs); i++ {
-		lastPos = i
-		attr := attrs[i]
-		if attr.HasCalled {
+func getTokenAttributes(query string) []*structs.TokenAttribute {
+	tokenAttributes, _, _ := tokenize(query)
+	return tokenAttributes
+}
+
+// tokenize splits the query into token attributes and returns, next to them,
+// the byte offset in the query where every token starts.
+func tokenize(query string) ([]*structs.TokenAttribute, []int, error) {
+	t := &tokenizer{}
+	isOpenQuote, skipTo := false, 0
+	for i, char := range query {
+		if i < skipTo {
 			continue
 		}
-		attr.HasCalled = true
-		if attr.Value == ")" {
-			break
-		}
-		if attr.Value == "(" {
-			newCondition := structs.Condition{
-				Operator: operator,
+		// inside quotes backslashes only escape when they come right before a
+		// quote: every pair stands for one backslash and an odd one left over
+		// makes the quote literal. Other backslashes are kept as they are so
+		// regex escapes such as \\d read the same as before.
+		if isOpenQuote && char == '\\' {
+			end := i
+			for end < len(query) && query[end] == '\\' {
+				end++
 			}
-			lastPos, resp := buildCondition(newCondition, attrs[i+1:], attributeNames)
-			condition.Conditions = append(condition.Conditions, &resp)
-			i = i + lastPos + 1
-			continue
-		}
-
-		if val, ok := logicalOperatorMap[attr.Value]; ok {
-			operator = val
-			conditionItem = nil
-		} else if _, ok := operatorMap[attr.Value]; ok {
-			if conditionItem != nil {
-				conditionItem.Attribute.Operator = attr.Value
+			t.start(i)
+			skipTo = end
+			if end == len(query) || query[end] != '"' {
+				t.buffer.WriteString(query[i:end])
+				continue
 			}
-		} else {
-			if conditionItem == nil {
-				conditionItem = &structs.Condition{
-					Attribute: &structs.Attribute{
-						Name: attr.Value,
-					},
-				}
-				conditionItem.Attribute = &structs.Attribute{
-					Name: attr.Value,
-				}
-				attributeNames[attr.Value] = nil
-			} else {
-				conditionItem.Attribute.Value = attr.Value
-				if !attr.IsAlphanumeric {
-					conditionItem.Attribute.Type = getValueType(attr.Value)
-				}
-				if condition.Conditions == nil {
-					condition.Conditions = []*structs.Condition{}
+			count := end - i
+			t.buffer.WriteString(strings.Repeat(`\`, count/2))
+			if count%2 == 1 {
+				// a query such as `Path = "C:\"` has no other quote to close
+				// it, the backslash is then kept and the quote closes
+				if strings.IndexByte(query[end+1:], '"') < 0 {
+					t.buffer.WriteByte('\\')
+					continue
 				}
-				conditionItem.Operator = operator
-				condition.Conditions = append(condition.Conditions, conditionItem)
+				t.buffer.WriteByte('"')
+				skipTo = end + 1
 			}
+			continue
 		}
-	}
-	return lastPos, condition
-}
-
-func getTokenAttributes(query string) []*structs.TokenAttribute {
-	var tokenAttributes []*structs.TokenAttribute
-	buffer := &bytes.Buffer{}
-	isOpenQuote := false
-	isAlphanumeric := false
-	for _, char := range query {
 		switch char {
-		case ' ', '\n', '\'':
+		case ' ', '\n', '\r', '\t', '\'':
 			if !isOpenQuote {
+				if t.buffer.Len() > 0 {
+					t.flush(t.buffer.String())
+				}
 				continue
 			} else {
-				buffer.WriteRune(char)
+				t.write(i, char)
 			}
+		case ',':
+			// a comma only separates in (...) list values, the collection of
+			// a quantifier and clause arguments, anywhere else it is part of
+			// the value as in 1,000
+			if isOpenQuote || !t.isSeparator() {
+				t.write(i, char)
+				continue
+			}
+			if t.buffer.Len() > 0 {
+				t.flush(t.buffer.String())
+			}
+			t.emit(i, string(char))
 		case '|', '&', '<', '>', '!':
-			if buffer.Len() > 0 {
-				bufBytes := buffer.Bytes()
+			if isOpenQuote {
+				t.write(i, char)
+				continue
+			}
+			if t.buffer.Len() > 0 {
+				bufBytes := t.buffer.Bytes()
 				switch bufBytes[0] {
 				case bytescodes.ByteVerticalBar:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, logicaloperators.LogicalOperatorOrSyntax, isAlphanumeric)
-					isAlphanumeric = false
+					t.flush(logicaloperators.LogicalOperatorOrSyntax)
 				case bytescodes.ByteAmpersand:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, logicaloperators.LogicalOperatorAndSyntax, isAlphanumeric)
-					isAlphanumeric = false
+					t.flush(logicaloperators.LogicalOperatorAndSyntax)
 				default:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes), isAlphanumeric)
-					isAlphanumeric = false
-					buffer.WriteRune(char)
+					t.flush(string(bufBytes))
+					t.write(i, char)
 				}
 			} else {
-				buffer.WriteRune(char)
+				t.write(i, char)
 			}
 		case '=', '(', ')', '~':
-			if buffer.Len() > 0 {
-				bufBytes := buffer.Bytes()
+			if isOpenQuote {
+				t.write(i, char)
+				continue
+			}
+			if t.buffer.Len() > 0 {
+				bufBytes := t.buffer.Bytes()
 				switch bufBytes[0] {
 				case bytescodes.ByteLessThan, bytescodes.ByteGreaterThan:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes)+string(char), isAlphanumeric)
-					isAlphanumeric = false
+					t.flush(string(bufBytes) + string(char))
 					continue
 				case bytescodes.ByteVerticalBar, bytescodes.ByteExclamation:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes)+string(char), isAlphanumeric)
-					isAlphanumeric = false
+					if char == '(' && len(bufBytes) == 1 && bufBytes[0] == bytescodes.ByteExclamation {
+						t.flush(logicaloperators.LogicalOperatorNotSyntax)
+						break
+					}
+					t.flush(string(bufBytes) + string(char))
 					continue
 				default:
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufBytes), isAlphanumeric)
-					isAlphanumeric = false
+					t.flush(string(bufBytes))
 				}
 			}
-			tokenAttributes = append(tokenAttributes, &structs.TokenAttribute{
-				Value: string(char),
-			})
+			t.openOrClose(char)
+			t.emit(i, string(char))
 		case '"':
+			if !isOpenQuote {
+				t.start(i)
+			}
 			isOpenQuote = !isOpenQuote
 			if !isOpenQuote {
-				isAlphanumeric = true
+				t.isAlphanumeric = true
+				t.flush(t.buffer.String())
 			}
-		case '\t':
-			// ignore
 		default:
-			if buffer.Len() > 0 {
-				bufByte := buffer.Bytes()[0]
-				if bufByte == bytescodes.ByteLessThan || bufByte == bytescodes.ByteGreaterThan {
-					tokenAttributes = appendAttribute(tokenAttributes, buffer, string(bufByte), isAlphanumeric)
-					isAlphanumeric = false
+			if t.buffer.Len() > 0 {
+				bufByte := t.buffer.Bytes()[0]
+				// a ! that starts a value such as `Name = !x` is part of it
+				isValue := bufByte == bytescodes.ByteExclamation && t.isValueStart()
+				if !isValue && (bufByte == bytescodes.ByteLessThan || bufByte == bytescodes.ByteGreaterThan || bufByte == bytescodes.ByteExclamation || bufByte == bytescodes.ByteVerticalBar) {
+					t.flush(string(bufByte))
 				}
 			}
-			buffer.WriteRune(char)
+			t.write(i, char)
 		}
 	}
-	if buffer.Len() > 0 {
-		tokenAttributes = appendAttribute(tokenAttributes, buffer, buffer.String(), isAlphanumeric)
-		isAlphanumeric = false
+	if isOpenQuote {
+		return t.tokenAttributes, t.offsets, newParseError(query, t.bufferOffset, query[t.bufferOffset:], "closing '\"'")
 	}
-	return tokenAttributes
+	if t.buffer.Len() > 0 {
+		t.flush(t.buffer.String())
+	}
+	return t.tokenAttributes, t.offsets, nil
+}
+
+type tokenizer struct {
+	tokenAttributes []*structs.TokenAttribute
+	offsets         []int
+	buffer          bytes.Buffer
+	bufferOffset    int
+	isPending       bool
+	isAlphanumeric  bool
+	groups          []groupKind
+	isClause        bool
+}
+
+// groupKind tells what an open parenthesis started, which decides whether a
+// comma inside it is a separator.
+type groupKind int
+
+const (
+	groupPlain groupKind = iota
+	groupList
+	groupQuantifier
+)
+
+// openOrClose keeps track of the open parentheses when char is one.
+func (t *tokenizer) openOrClose(char rune) {
+	switch char {
+	case '(':
+		kind := groupPlain
+		if n := len(t.tokenAttributes); n > 0 {
+			last := t.tokenAttributes[n-1]
+			if isKeyword(last, operators.OperatorIn) {
+				kind = groupList
+			} else if isQuantifier(last) {
+				kind = groupQuantifier
+			}
+		}
+		t.groups = append(t.groups, kind)
+	case ')':
+		if len(t.groups) > 0 {
+			t.groups = t.groups[:len(t.groups)-1]
+		}
+	}
+}
+
+// isValueStart reports whether the pending token is read where a value is
+// expected: after a comparison operator or at the start of a list value.
+func (t *tokenizer) isValueStart() bool {
+	n := len(t.tokenAttributes)
+	if n == 0 {
+		return false
+	}
+	last := t.tokenAttributes[n-1]
+	if isOperator(last) {
+		return true
+	}
+	return len(t.groups) > 0 && t.groups[len(t.groups)-1] == groupList && (isSymbol(last, "(") || isSymbol(last, ","))
 }
 
-func appendAttribute(tokenAttributes []*structs.TokenAttribute, buffer *bytes.Buffer, value string, isAlphanumeric bool) []*structs.TokenAttribute {
-	tokenAttributes = append(tokenAttributes, &structs.TokenAttribute{
+// isSeparator reports whether a comma read now separates tokens. Only the
+// first comma of a quantifier is one, the rest belong to its sequence.
+func (t *tokenizer) isSeparator() bool {
+	if len(t.groups) == 0 {
+		return t.isClause
+	}
+	switch top := &t.groups[len(t.groups)-1]; *top {
+	case groupList:
+		return true
+	case groupQuantifier:
+		*top = groupPlain
+		return true
+	}
+	return false
+}
+
+// start marks offset as the beginning of the pending token unless one is
+// already in progress.
+func (t *tokenizer) start(offset int) {
+	if !t.isPending {
+		t.bufferOffset = offset
+		t.isPending = true
+	}
+}
+
+// write appends char to the pending token.
+func (t *tokenizer) write(offset int, char rune) {
+	t.start(offset)
+	t.buffer.WriteRune(char)
+}
+
+// flush turns the pending token into a token attribute holding value.
+func (t *tokenizer) flush(value string) {
+	if value == clauseSeparator && !t.isAlphanumeric && len(t.groups) == 0 {
+		t.isClause = true
+	}
+	t.tokenAttributes = append(t.tokenAttributes, &structs.TokenAttribute{
 		Value:          value,
-		IsAlphanumeric: isAlphanumeric,
+		IsAlphanumeric: t.isAlphanumeric,
 	})
-	buffer.Reset()
-	return tokenAttributes
+	t.offsets = append(t.offsets, t.bufferOffset)
+	t.buffer.Reset()
+	t.isPending = false
+	t.isAlphanumeric = false
 }
 
-func getValueType(value string) valuetypes.ValueType {
+// emit appends a single character token that starts at offset.
+func (t *tokenizer) emit(offset int, value string) {
+	t.tokenAttributes = append(t.tokenAttributes, &structs.TokenAttribute{
+		Value: value,
+	})
+	t.offsets = append(t.offsets, offset)
+}
+
+// ValueType infers the type of an unquoted condition value the way the
+// parser does: a number, an RFC 3339 date, or alphanumeric otherwise.
+func ValueType(value string) valuetypes.ValueType {
 	varType, indexVal, dotCount := valuetypes.Alphanumeric, 0, 0
 	for _, char := range value {
 		if char == ',' {
@@ -219,3 +384,24 @@ func getValueType(value string) valuetypes.ValueType {
 	}
 	return varType
 }
+
+// TypedValue converts a condition value to the Go value of its type: a
+// number to an int64 or a float64, a date to a time.Time and anything else,
+// quoted values included, to a string. The generators use it so a value is
+// typed the same way in every query language.
+func TypedValue(value string, valueType valuetypes.ValueType) interface{} {
+	switch valueType {
+	case valuetypes.Numeric:
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case valuetypes.Date:
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			return date
+		}
+	}
+	return value
+}
@@ -3,10 +3,12 @@ package structgen
 import (
 	"bytes"
 	"encoding/json"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
 	"github.com/ahmadrezamusthafa/deep-validator/structs"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGenerateConditionQueryStructure(t *testing.T) {
@@ -61,7 +63,7 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
                       ) && user_id = 43
 `,
 			},
-			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}`,
+			want:    `{"conditions":[{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]},{"operator":"OR","conditions":[{"conditions":[{"attribute":{"name":"division","operator":"=","value":"engineering","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"division","operator":"=","value":"finance","type":"alphanumeric"}}]},{"operator":"AND","attribute":{"name":"user_id","operator":"=","value":"43","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
 		{
@@ -169,6 +171,70 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
 			want:    `{"conditions":[{"conditions":[{"conditions":[{"attribute":{"name":"date","operator":"\u003c=","value":"2019-09-09","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"date","operator":"\u003e","value":"2019-08-08","type":"alphanumeric"}}]},{"operator":"OR","conditions":[{"attribute":{"name":"p_date","operator":"\u003e=","value":"2019-01-01","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"p_date","operator":"\u003c","value":"2019-02-02","type":"alphanumeric"}}]}]},{"operator":"AND","conditions":[{"attribute":{"name":"member_type","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"member_type","operator":"=","value":"2","type":"numeric"}}]}]}`,
 			wantErr: false,
 		},
+		{
+			name: "Normal case - negation",
+			args: args{
+				query: `id = 1 && !(status = closed || status = archived) && !member_id = 2`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","negate":true,"conditions":[{"attribute":{"name":"status","operator":"=","value":"closed","type":"alphanumeric"}},{"operator":"OR","attribute":{"name":"status","operator":"=","value":"archived","type":"alphanumeric"}}]},{"operator":"AND","negate":true,"attribute":{"name":"member_id","operator":"=","value":"2","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - double negation",
+			args: args{
+				query: `!!id = 1 && member_id != 2`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"id","operator":"=","value":"1","type":"numeric"}},{"operator":"AND","attribute":{"name":"member_id","operator":"!=","value":"2","type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - in and not in",
+			args: args{
+				query: `PartnerId in ("bca", "bni",bri) && member_id NOT IN (1, 2, 3) && id in (1, 2.5)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"PartnerId","operator":"in","value":"","values":["bca","bni","bri"]}},{"operator":"AND","attribute":{"name":"member_id","operator":"not in","value":"","values":["1","2","3"],"type":"numeric"}},{"operator":"AND","attribute":{"name":"id","operator":"in","value":"","values":["1","2.5"],"type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - field reference",
+			args: args{
+				query: `Debit = $TotalAmount && CompletedAt >= $Payload.ConfirmedAt && name = "$literal" && id = $`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"Debit","operator":"=","value":"TotalAmount","type":"field"}},{"operator":"AND","attribute":{"name":"CompletedAt","operator":"\u003e=","value":"Payload.ConfirmedAt","type":"field"}},{"operator":"AND","attribute":{"name":"name","operator":"=","value":"$literal"}},{"operator":"AND","attribute":{"name":"id","operator":"=","value":"$","type":"alphanumeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - quantifiers and len",
+			args: args{
+				query: `any(Items, Items.Amount > 1000) && all(Tags, Tags != "test") && !none(Items, len(Items.Codes) >= 3)`,
+			},
+			want:    `{"conditions":[{"quantifier":"any","collection":"Items","conditions":[{"attribute":{"name":"Items.Amount","operator":"\u003e","value":"1000","type":"numeric"}}]},{"operator":"AND","quantifier":"all","collection":"Tags","conditions":[{"attribute":{"name":"Tags","operator":"!=","value":"test"}}]},{"operator":"AND","negate":true,"quantifier":"none","collection":"Items","conditions":[{"attribute":{"name":"Items.Codes","function":"len","operator":"\u003e=","value":"3","type":"numeric"}}]}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - commas in values",
+			args: args{
+				query: `Amount = 1,000 && Tags = a,b && (Amount >= 2,000) && any(Items, Items.Amount > 1,000) && id in (1,000)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"Amount","operator":"=","value":"1,000","type":"numeric"}},{"operator":"AND","attribute":{"name":"Tags","operator":"=","value":"a,b","type":"alphanumeric"}},{"operator":"AND","conditions":[{"attribute":{"name":"Amount","operator":"\u003e=","value":"2,000","type":"numeric"}}]},{"operator":"AND","quantifier":"any","collection":"Items","conditions":[{"attribute":{"name":"Items.Amount","operator":"\u003e","value":"1,000","type":"numeric"}}]},{"operator":"AND","attribute":{"name":"id","operator":"in","value":"","values":["1","000"],"type":"numeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - values starting with !",
+			args: args{
+				query: `Name = !x && !Code != !y && Tag in (!a, b)`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"Name","operator":"=","value":"!x","type":"alphanumeric"}},{"operator":"AND","negate":true,"attribute":{"name":"Code","operator":"!=","value":"!y","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"Tag","operator":"in","value":"","values":["!a","b"],"type":"alphanumeric"}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Normal case - exists and null",
+			args: args{
+				query: `exists(Payload.SenderType) && SenderType IS NULL && !Partner is not null`,
+			},
+			want:    `{"conditions":[{"attribute":{"name":"Payload.SenderType","operator":"exists","value":""}},{"operator":"AND","attribute":{"name":"SenderType","operator":"is null","value":""}},{"operator":"AND","negate":true,"attribute":{"name":"Partner","operator":"is not null","value":""}}]}`,
+			wantErr: false,
+		},
 	}
 	s := StructGen{}
 	for _, tt := range tests {
@@ -182,6 +248,9 @@ func TestGenerateConditionQueryStructure(t *testing.T) {
 			if !strings.EqualFold(string(byteBuf), tt.want) {
 				t.Errorf("GenerateCondition() = %v, want %v", string(byteBuf), tt.want)
 			}
+			if printed, err := s.GenerateCondition(Print(got)); err != nil || !reflect.DeepEqual(printed, got) {
+				t.Errorf("GenerateCondition(Print()) = %+v, %v, want %+v", printed, err, got)
+			}
 		})
 	}
 }
@@ -554,6 +623,114 @@ func Test_getToken(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Normal case - list",
+			args: args{
+				value: `partner_id not in ("bca",bni)`,
+			},
+			want: []*structs.TokenAttribute{
+				{
+					Value: "partner_id",
+				},
+				{
+					Value: "not",
+				},
+				{
+					Value: "in",
+				},
+				{
+					Value: "(",
+				},
+				{
+					Value:          "bca",
+					IsAlphanumeric: true,
+				},
+				{
+					Value: ",",
+				},
+				{
+					Value: "bni",
+				},
+				{
+					Value: ")",
+				},
+			},
+		},
+		{
+			name: "Normal case - backslashes",
+			args: args{
+				value: `path |~ "a\\d" && note = "say \"hi\""`,
+			},
+			want: []*structs.TokenAttribute{
+				{
+					Value: "path",
+				},
+				{
+					Value: "|~",
+				},
+				{
+					Value:          `a\\d`,
+					IsAlphanumeric: true,
+				},
+				{
+					Value: "&&",
+				},
+				{
+					Value: "note",
+				},
+				{
+					Value: "=",
+				},
+				{
+					Value:          `say "hi"`,
+					IsAlphanumeric: true,
+				},
+			},
+		},
+		{
+			name: "Normal case - backslashes before a quote",
+			args: args{
+				value: `path = "C:\\" || note = "a\\\"b" || old = "C:\"`,
+			},
+			want: []*structs.TokenAttribute{
+				{
+					Value: "path",
+				},
+				{
+					Value: "=",
+				},
+				{
+					Value:          `C:\`,
+					IsAlphanumeric: true,
+				},
+				{
+					Value: "||",
+				},
+				{
+					Value: "note",
+				},
+				{
+					Value: "=",
+				},
+				{
+					Value:          `a\"b`,
+					IsAlphanumeric: true,
+				},
+				{
+					Value: "||",
+				},
+				{
+					Value: "old",
+				},
+				{
+					Value: "=",
+				},
+				{
+					Value:          `C:\`,
+					IsAlphanumeric: true,
+				},
+			},
+		},
 		{
 			name: "Nil case",
 			args: args{
@@ -579,3 +756,357 @@ func Test_getToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateConditionSyntaxError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  ParseError
+	}{
+		{
+			name:  "Error case - unbalanced open parenthesis",
+			query: `(id=1 && member_id=2`,
+			want:  ParseError{Offset: 20, Line: 1, Column: 21, Expected: "'&&', '||' or ')'"},
+		},
+		{
+			name:  "Error case - unbalanced close parenthesis",
+			query: `id=1 && member_id=2)`,
+			want:  ParseError{Offset: 19, Line: 1, Column: 20, Token: ")", Expected: "'&&', '||' or end of query"},
+		},
+		{
+			name:  "Error case - dangling logical operator",
+			query: `id=1 &&`,
+			want:  ParseError{Offset: 7, Line: 1, Column: 8, Expected: "attribute name, '!' or '('"},
+		},
+		{
+			name:  "Error case - operator without value",
+			query: "id=1\n  && member_id=",
+			want:  ParseError{Offset: 20, Line: 2, Column: 16, Expected: "value"},
+		},
+		{
+			name:  "Error case - unknown operator",
+			query: `id=>1`,
+			want:  ParseError{Offset: 2, Line: 1, Column: 3, Token: "=>", Expected: "comparison operator"},
+		},
+		{
+			name:  "Error case - missing logical operator",
+			query: `(id=1)(member_id=2)`,
+			want:  ParseError{Offset: 6, Line: 1, Column: 7, Token: "(", Expected: "'&&', '||' or end of query"},
+		},
+		{
+			name:  "Error case - empty group",
+			query: `id=1 && ()`,
+			want:  ParseError{Offset: 9, Line: 1, Column: 10, Token: ")", Expected: "attribute name, '!' or '('"},
+		},
+		{
+			name:  "Error case - unterminated list",
+			query: `id in (1, 2`,
+			want:  ParseError{Offset: 11, Line: 1, Column: 12, Expected: "',' or ')'"},
+		},
+		{
+			name:  "Error case - empty list",
+			query: `id not in ()`,
+			want:  ParseError{Offset: 11, Line: 1, Column: 12, Token: ")", Expected: "value"},
+		},
+		{
+			name:  "Error case - quantifier without collection",
+			query: `any(, Items.Amount > 1)`,
+			want:  ParseError{Offset: 4, Line: 1, Column: 5, Token: ",", Expected: "collection attribute name"},
+		},
+		{
+			name:  "Error case - quantifier without condition",
+			query: `all(Items)`,
+			want:  ParseError{Offset: 9, Line: 1, Column: 10, Token: ")", Expected: "','"},
+		},
+		{
+			name:  "Error case - unclosed len",
+			query: `len(Items >= 3`,
+			want:  ParseError{Offset: 10, Line: 1, Column: 11, Token: ">=", Expected: "')'"},
+		},
+		{
+			name:  "Error case - is without null",
+			query: `SenderType is bca`,
+			want:  ParseError{Offset: 14, Line: 1, Column: 15, Token: "bca", Expected: "'null' or 'not null'"},
+		},
+		{
+			name:  "Error case - is not without null",
+			query: `SenderType is not`,
+			want:  ParseError{Offset: 17, Line: 1, Column: 18, Expected: "'null'"},
+		},
+		{
+			name:  "Error case - unclosed exists",
+			query: `exists(SenderType && id = 1`,
+			want:  ParseError{Offset: 18, Line: 1, Column: 19, Token: "&&", Expected: "')'"},
+		},
+		{
+			name:  "Error case - unterminated quote",
+			query: `name="reza`,
+			want:  ParseError{Offset: 5, Line: 1, Column: 6, Token: `"reza`, Expected: `closing '"'`},
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GenerateCondition(tt.query)
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("GenerateCondition() error = %v, want *ParseError", err)
+			}
+			if !reflect.DeepEqual(*parseErr, tt.want) {
+				t.Errorf("GenerateCondition() error = %+v, want %+v", *parseErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateBaseCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr *ParseError
+	}{
+		{
+			name:  "Normal case - every clause",
+			query: `status=active && amount>100 | sort createdAt desc, id asc | limit 20 offset 40 | fields id,amount | distinct`,
+			want:  `{"fields":["id","amount"],"distinct":true,"conditions":[{"attribute":{"name":"status","operator":"=","value":"active","type":"alphanumeric"}},{"operator":"AND","attribute":{"name":"amount","operator":"\u003e","value":"100","type":"numeric"}}],"footer":{"page":0,"limit":20,"offset":40,"sort":{"createdAt":"desc","id":"asc"},"sortKeys":["createdAt","id"]}}`,
+		},
+		{
+			name:  "Normal case - clauses without spaces and in any case",
+			query: `(a=1||b=2)|SORT name|Page 3|LIMIT 10`,
+			want:  `{"distinct":false,"conditions":[{"conditions":[{"attribute":{"name":"a","operator":"=","value":"1","type":"numeric"}},{"operator":"OR","attribute":{"name":"b","operator":"=","value":"2","type":"numeric"}}]}],"footer":{"page":3,"limit":10,"sort":{"name":"asc"},"sortKeys":["name"]}}`,
+		},
+		{
+			name:  "Normal case - comma in a value before clauses",
+			query: `amount = 1,000 | fields id,amount`,
+			want:  `{"fields":["id","amount"],"distinct":false,"conditions":[{"attribute":{"name":"amount","operator":"=","value":"1,000","type":"numeric"}}],"footer":{"page":0,"limit":0,"sort":null}}`,
+		},
+		{
+			name:  "Normal case - clauses only",
+			query: `| fields "first name" | offset 5`,
+			want:  `{"fields":["first name"],"distinct":false,"footer":{"page":0,"limit":0,"offset":5,"sort":null}}`,
+		},
+		{
+			name:  "Normal case - empty query",
+			query: ``,
+			want:  `{"distinct":false,"footer":{"page":0,"limit":0,"sort":null}}`,
+		},
+		{
+			name:    "Error case - unknown clause",
+			query:   `a=1 | order id`,
+			wantErr: &ParseError{Offset: 6, Line: 1, Column: 7, Token: "order", Expected: "sort, limit, offset, page, fields or distinct"},
+		},
+		{
+			name:    "Error case - limit without count",
+			query:   `a=1 | limit ten`,
+			wantErr: &ParseError{Offset: 12, Line: 1, Column: 13, Token: "ten", Expected: "non-negative integer"},
+		},
+		{
+			name:    "Error case - dangling separator",
+			query:   `a=1 | sort id |`,
+			wantErr: &ParseError{Offset: 15, Line: 1, Column: 16, Expected: "sort, limit, offset, page, fields or distinct"},
+		},
+		{
+			name:    "Error case - sort without field",
+			query:   `a=1 | sort , id`,
+			wantErr: &ParseError{Offset: 11, Line: 1, Column: 12, Token: ",", Expected: "sort field name"},
+		},
+		{
+			name:    "Error case - garbage after clause",
+			query:   `a=1 | distinct now`,
+			wantErr: &ParseError{Offset: 15, Line: 1, Column: 16, Token: "now", Expected: "'&&', '||', '|' or end of query"},
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GenerateBaseCondition(tt.query)
+			if tt.wantErr != nil {
+				parseErr, ok := err.(*ParseError)
+				if !ok || !reflect.DeepEqual(*parseErr, *tt.wantErr) {
+					t.Errorf("GenerateBaseCondition() error = %+v, want %+v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateBaseCondition() error = %v", err)
+			}
+			byteBuf, _ := json.Marshal(got)
+			if string(byteBuf) != tt.want {
+				t.Errorf("GenerateBaseCondition() = %v, want %v", string(byteBuf), tt.want)
+			}
+		})
+	}
+
+	if _, err := s.GenerateCondition(`a=1 | sort id`); err == nil {
+		t.Errorf("GenerateCondition() error = nil, want clauses rejected")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       string
+		wantPretty string
+	}{
+		{
+			name:       "Normal case - groups",
+			query:      `(id=1 && member_id=2)||( division = engineering || division=finance )`,
+			want:       `(id = 1 && member_id = 2) || (division = engineering || division = finance)`,
+			wantPretty: "(\n  id = 1\n  && member_id = 2\n)\n|| (\n  division = engineering\n  || division = finance\n)",
+		},
+		{
+			name:       "Normal case - negation, lists and references",
+			query:      `!!id = 1 && !(status=closed) && PartnerId in ("bca", bni) && member_id NOT IN (1, 2) && Debit=$TotalAmount`,
+			want:       `id = 1 && !(status = closed) && PartnerId in ("bca", "bni") && member_id not in (1, 2) && Debit = $TotalAmount`,
+			wantPretty: "id = 1\n&& !(\n  status = closed\n)\n&& PartnerId in (\"bca\", \"bni\")\n&& member_id not in (1, 2)\n&& Debit = $TotalAmount",
+		},
+		{
+			name:       "Normal case - quantifiers and len",
+			query:      `any(Items, Items.Amount > 1000 && !none(Items.Tags, Items.Tags = x)) || len(Items)>=3`,
+			want:       `any(Items, Items.Amount > 1000 && !none(Items.Tags, Items.Tags = x)) || len(Items) >= 3`,
+			wantPretty: "any(Items,\n  Items.Amount > 1000\n  && !none(Items.Tags,\n    Items.Tags = x\n  )\n)\n|| len(Items) >= 3",
+		},
+		{
+			name:       "Normal case - exists and null",
+			query:      `exists("sender type") || !(SenderType IS NOT NULL) && Partner is null`,
+			want:       `exists("sender type") || (!(SenderType is not null) && Partner is null)`,
+			wantPretty: "exists(\"sender type\")\n|| (\n  !(\n    SenderType is not null\n  )\n  && Partner is null\n)",
+		},
+		{
+			name:       "Normal case - quoting",
+			query:      `"first name" = "reza m" && note |= "a \"b\" \\ c" && tag = "$x" && code = "1" && empty = "" && regex |~ "[\s]&&(x)"`,
+			want:       `"first name" = "reza m" && note |= "a \"b\" \\ c" && tag = "$x" && code = "1" && empty = "" && regex |~ "[\s]&&(x)"`,
+			wantPretty: "\"first name\" = \"reza m\"\n&& note |= \"a \\\"b\\\" \\\\ c\"\n&& tag = \"$x\"\n&& code = \"1\"\n&& empty = \"\"\n&& regex |~ \"[\\s]&&(x)\"",
+		},
+		{
+			name:       "Normal case - backslashes",
+			query:      `path = "C:\\" && note = "a\\\"b" && regex |~ "a\\d\\" && old = "C:\"`,
+			want:       `path = "C:\\" && note = "a\\\"b" && regex |~ "a\\d\\" && old = "C:\\"`,
+			wantPretty: "path = \"C:\\\\\"\n&& note = \"a\\\\\\\"b\"\n&& regex |~ \"a\\\\d\\\\\"\n&& old = \"C:\\\\\"",
+		},
+		{
+			name:       "Normal case - empty query",
+			query:      ``,
+			want:       ``,
+			wantPretty: ``,
+		},
+	}
+	s := StructGen{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, err := s.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			if got := Print(condition); got != tt.want {
+				t.Errorf("Print() = %v, want %v", got, tt.want)
+			}
+			if got := PrintPretty(condition); got != tt.wantPretty {
+				t.Errorf("PrintPretty() = %v, want %v", got, tt.wantPretty)
+			}
+			for _, printed := range []string{Print(condition), PrintPretty(condition)} {
+				got, err := s.GenerateCondition(printed)
+				if err != nil {
+					t.Fatalf("GenerateCondition(%q) error = %v", printed, err)
+				}
+				if !reflect.DeepEqual(got, condition) {
+					t.Errorf("GenerateCondition(%q) = %+v, want %+v", printed, got, condition)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCondition_Precedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       string
+		wantLegacy string
+	}{
+		{
+			name:       "Normal case - and after or",
+			query:      `a = 1 || b = 2 && c = 3`,
+			want:       `a = 1 || (b = 2 && c = 3)`,
+			wantLegacy: `(a = 1 || b = 2) && c = 3`,
+		},
+		{
+			name:       "Normal case - several runs",
+			query:      `a = 1 && b = 2 || c = 3 && d = 4 || e = 5`,
+			want:       `a = 1 && b = 2 || (c = 3 && d = 4) || e = 5`,
+			wantLegacy: `(a = 1 && b = 2 || c = 3) && d = 4 || e = 5`,
+		},
+		{
+			name:       "Normal case - groups and quantifiers",
+			query:      `(a = 1 || b = 2 && c = 3) && any(Items, Items.x = 1 || Items.y = 2 && Items.z = 3)`,
+			want:       `(a = 1 || (b = 2 && c = 3)) && any(Items, Items.x = 1 || (Items.y = 2 && Items.z = 3))`,
+			wantLegacy: `((a = 1 || b = 2) && c = 3) && any(Items, (Items.x = 1 || Items.y = 2) && Items.z = 3)`,
+		},
+		{
+			name:       "Normal case - unchanged without and after or",
+			query:      `a = 1 && b = 2 || c = 3`,
+			want:       `a = 1 && b = 2 || c = 3`,
+			wantLegacy: `a = 1 && b = 2 || c = 3`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := StructGen{}
+			got, err := gen.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() error = %v", err)
+			}
+			if Print(got) != tt.want {
+				t.Errorf("GenerateCondition() = %s, want %s", Print(got), tt.want)
+			}
+			legacy := StructGen{LegacyPrecedence: true}
+			got, err = legacy.GenerateCondition(tt.query)
+			if err != nil {
+				t.Fatalf("GenerateCondition() legacy error = %v", err)
+			}
+			if Print(got) != tt.wantLegacy {
+				t.Errorf("GenerateCondition() legacy = %s, want %s", Print(got), tt.wantLegacy)
+			}
+			// the printed legacy tree means the same under either precedence
+			reparsed, err := gen.GenerateCondition(Print(got))
+			if err != nil {
+				t.Fatalf("GenerateCondition(Print()) error = %v", err)
+			}
+			if Print(reparsed) != tt.wantLegacy {
+				t.Errorf("GenerateCondition(Print()) = %s, want %s", Print(reparsed), tt.wantLegacy)
+			}
+		})
+	}
+}
+
+func TestTypedValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		wantValueType valuetypes.ValueType
+		want          interface{}
+	}{
+		{name: "Integer", value: "12", wantValueType: valuetypes.Numeric, want: int64(12)},
+		{name: "Float", value: "12.5", wantValueType: valuetypes.Numeric, want: 12.5},
+		{name: "Number with a comma", value: "1,000", wantValueType: valuetypes.Numeric, want: "1,000"},
+		{name: "Date", value: "2024-01-02T03:04:05Z", wantValueType: valuetypes.Date, want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "Alphanumeric", value: "reza", wantValueType: valuetypes.Alphanumeric, want: "reza"},
+		{name: "Negative number", value: "-1", wantValueType: valuetypes.Alphanumeric, want: "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valueType := ValueType(tt.value)
+			if valueType != tt.wantValueType {
+				t.Errorf("ValueType(%s) = %v, want %v", tt.value, valueType, tt.wantValueType)
+			}
+			if got := TypedValue(tt.value, valueType); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TypedValue(%s) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+	if got := TypedValue("12", ""); got != "12" {
+		t.Errorf("TypedValue(12) of a quoted value = %#v, want \"12\"", got)
+	}
+}
@@ -6,8 +6,10 @@ import (
 
 type Attribute struct {
 	Name     string               `json:"name"`
+	Function string               `json:"function,omitempty"`
 	Operator string               `json:"operator"`
 	Value    string               `json:"value"`
+	Values   []string             `json:"values,omitempty"`
 	Type     valuetypes.ValueType `json:"type,omitempty"`
 }
 
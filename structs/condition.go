@@ -1,5 +1,9 @@
 package structs
 
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+)
+
 type BaseCondition struct {
 	Fields     []string     `json:"fields,omitempty"`
 	Distinct   bool         `json:"distinct"`
@@ -9,6 +13,45 @@ type BaseCondition struct {
 
 type Condition struct {
 	Operator   string       `json:"operator,omitempty"`
+	Negate     bool         `json:"negate,omitempty"`
+	Quantifier string       `json:"quantifier,omitempty"`
+	Collection string       `json:"collection,omitempty"`
 	Attribute  *Attribute   `json:"attribute,omitempty"`
 	Conditions []*Condition `json:"conditions,omitempty"`
 }
+
+// IsEmpty reports whether the condition is nil or the condition of an empty
+// query, which matches everything.
+func (c *Condition) IsEmpty() bool {
+	return c == nil || (len(c.Conditions) == 0 && c.Quantifier == "" &&
+		(c.Attribute == nil || c.Attribute.Name == ""))
+}
+
+// Fold folds the terms of a group left to right, the order the validator
+// evaluates them in, so `a || b && c` is read as `(a || b) && c`. term
+// translates every condition and join a run of terms sharing the logical
+// operator, logicaloperators.LogicalOperatorAnd or LogicalOperatorOr. The
+// last run isn't joined, it is returned with its operator for the caller to
+// join, or to take as it is when it holds one term.
+func Fold[T any](conditions []*Condition, term func(*Condition) (T, error), join func(operator string, terms []T) T) (operator string, terms []T, err error) {
+	for i, condition := range conditions {
+		translated, err := term(condition)
+		if err != nil {
+			return "", nil, err
+		}
+		if i == 0 {
+			terms = append(terms, translated)
+			continue
+		}
+		next := logicaloperators.LogicalOperatorAnd
+		if condition.Operator == logicaloperators.LogicalOperatorOr {
+			next = logicaloperators.LogicalOperatorOr
+		}
+		if operator != "" && operator != next {
+			terms = []T{join(operator, terms)}
+		}
+		operator = next
+		terms = append(terms, translated)
+	}
+	return operator, terms, nil
+}
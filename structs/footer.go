@@ -1,7 +1,13 @@
 package structs
 
+// Footer pages and orders the result of a query. Sort maps a field to "asc" or
+// "desc", SortKeys gives the order of the keys when sorting by more than one,
+// keys it doesn't list follow in alphabetical order. Offset skips items before
+// the page.
 type Footer struct {
-	Page  int               `json:"page"`
-	Limit int               `json:"limit"`
-	Sort  map[string]string `json:"sort"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset,omitempty"`
+	Sort     map[string]string `json:"sort"`
+	SortKeys []string          `json:"sortKeys,omitempty"`
 }
@@ -0,0 +1,168 @@
+package structs
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"strings"
+)
+
+// Trace is the evaluation of a condition node, mirroring Condition. Result is
+// the value of the node after negation, Skipped tells the node didn't take
+// part in the result of its group. Unknown is set instead of Result when the
+// node is unknown under the Unknown null policy.
+type Trace struct {
+	Operator   string          `json:"operator,omitempty"`
+	Negate     bool            `json:"negate,omitempty"`
+	Quantifier string          `json:"quantifier,omitempty"`
+	Collection string          `json:"collection,omitempty"`
+	Attribute  *AttributeTrace `json:"attribute,omitempty"`
+	Result     bool            `json:"result"`
+	Unknown    bool            `json:"unknown,omitempty"`
+	Skipped    bool            `json:"skipped,omitempty"`
+	Elements   []*ElementTrace `json:"elements,omitempty"`
+	Conditions []*Trace        `json:"conditions,omitempty"`
+}
+
+// AttributeTrace holds the field value a comparison resolved and the
+// condition value it was coerced to. Missing is set when the field path
+// couldn't be resolved.
+type AttributeTrace struct {
+	Name           string      `json:"name"`
+	Function       string      `json:"function,omitempty"`
+	Operator       string      `json:"operator"`
+	Value          string      `json:"value,omitempty"`
+	Values         []string    `json:"values,omitempty"`
+	Reference      string      `json:"reference,omitempty"`
+	FieldValue     interface{} `json:"fieldValue"`
+	ConditionValue interface{} `json:"conditionValue"`
+	Missing        bool        `json:"missing,omitempty"`
+}
+
+// ElementTrace is the evaluation of the conditions of a quantifier against one
+// element of its collection.
+type ElementTrace struct {
+	Index      int      `json:"index"`
+	Result     bool     `json:"result"`
+	Unknown    bool     `json:"unknown,omitempty"`
+	Conditions []*Trace `json:"conditions,omitempty"`
+}
+
+// NewTrace returns the trace node of condition before it is evaluated.
+func NewTrace(condition *Condition) *Trace {
+	trace := &Trace{
+		Operator:   condition.Operator,
+		Negate:     condition.Negate,
+		Quantifier: condition.Quantifier,
+		Collection: condition.Collection,
+	}
+	if condition.Attribute != nil && len(condition.Conditions) == 0 && condition.Quantifier == "" {
+		trace.Attribute = &AttributeTrace{
+			Name:     condition.Attribute.Name,
+			Function: condition.Attribute.Function,
+			Operator: condition.Attribute.Operator,
+			Value:    condition.Attribute.Value,
+			Values:   condition.Attribute.Values,
+		}
+		if condition.Attribute.Type == valuetypes.Field {
+			trace.Attribute.Reference, trace.Attribute.Value = condition.Attribute.Value, ""
+		}
+	}
+	return trace
+}
+
+// String renders the trace as an indented tree, one node per line.
+func (t *Trace) String() string {
+	var builder strings.Builder
+	t.write(&builder, 0)
+	return builder.String()
+}
+
+func (t *Trace) write(builder *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	result := fmt.Sprint(t.Result)
+	switch {
+	case t.Skipped:
+		result = "skipped"
+	case t.Unknown:
+		result = "unknown"
+	}
+	fmt.Fprintf(builder, "%s%-7s %s\n", indent, result, t.describe())
+	for _, element := range t.Elements {
+		result := fmt.Sprint(element.Result)
+		if element.Unknown {
+			result = "unknown"
+		}
+		fmt.Fprintf(builder, "%s  [%d] %s\n", indent, element.Index, result)
+		for _, condition := range element.Conditions {
+			condition.write(builder, depth+2)
+		}
+	}
+	for _, condition := range t.Conditions {
+		condition.write(builder, depth+1)
+	}
+}
+
+func (t *Trace) describe() string {
+	var parts []string
+	if t.Operator != "" {
+		parts = append(parts, t.Operator)
+	}
+	if t.Negate {
+		parts = append(parts, "NOT")
+	}
+	switch {
+	case t.Quantifier != "":
+		parts = append(parts, fmt.Sprintf("%s(%s)", t.Quantifier, t.Collection))
+	case t.Attribute != nil:
+		parts = append(parts, t.Attribute.describe(t.Skipped))
+	default:
+		parts = append(parts, "group")
+	}
+	return strings.Join(parts, " ")
+}
+
+// describe formats the comparison with the values it compared, a skipped
+// comparison compared none.
+func (a *AttributeTrace) describe(isSkipped bool) string {
+	name := a.Name
+	if a.Function != "" {
+		name = fmt.Sprintf("%s(%s)", a.Function, a.Name)
+	}
+	value := a.Value
+	switch {
+	case a.Reference != "":
+		value = "$" + a.Reference
+	case len(a.Values) > 0:
+		value = "(" + strings.Join(a.Values, ", ") + ")"
+	}
+	comparison := fmt.Sprintf("%s %s %s", name, a.Operator, value)
+	isPredicate := true
+	switch a.Operator {
+	case operators.OperatorExists:
+		comparison = fmt.Sprintf("%s(%s)", a.Operator, name)
+	case operators.OperatorIsNull, operators.OperatorIsNotNull:
+		comparison = fmt.Sprintf("%s %s", name, a.Operator)
+	default:
+		isPredicate = false
+	}
+	switch {
+	case isSkipped:
+		return comparison
+	case a.Missing:
+		return comparison + " [missing]"
+	case isPredicate:
+		return fmt.Sprintf("%s [field: %s]", comparison, formatTraceValue(a.FieldValue))
+	}
+	return fmt.Sprintf("%s [field: %s, value: %s]", comparison, formatTraceValue(a.FieldValue), formatTraceValue(a.ConditionValue))
+}
+
+func formatTraceValue(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return fmt.Sprintf("%q", val)
+	}
+	return fmt.Sprint(value)
+}
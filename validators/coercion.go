@@ -0,0 +1,151 @@
+package validators
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Converter converts a field value to a value it is compared as. The result
+// is converted again, so it can be of a numeric kind, a string, a bool, a
+// time.Time or of any other type the validators know how to compare. A nil
+// result is a null.
+type Converter func(value interface{}) (interface{}, error)
+
+// maxConversions bounds the conversions of one value, so a converter or a
+// driver.Valuer returning a value of its own type can't loop.
+const maxConversions = 16
+
+var (
+	converters sync.Map
+
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	bigIntType        = reflect.TypeOf(big.Int{})
+	bigRatType        = reflect.TypeOf(big.Rat{})
+)
+
+// RegisterConverter makes every validator compare the values of rType as
+// converter converts them, e.g. a money type as its amount in cents. A
+// converter registered for a type replaces the default conversion of the
+// type, nil removes it.
+func RegisterConverter(rType reflect.Type, converter Converter) {
+	if converter == nil {
+		converters.Delete(rType)
+		return
+	}
+	converters.Store(rType, converter)
+}
+
+// coerce converts a field value to the type it is compared as: int64 for the
+// signed integer kinds, uint64 for the unsigned ones, float32 or float64, bool,
+// string for the string kind, time.Time, or a *big.Rat for big.Int and
+// big.Rat. A registered converter comes first, then driver.Valuer, then the
+// kind of the value, and values of other kinds are compared as the text of
+// their encoding.TextMarshaler or fmt.Stringer, or as they print. json.Number
+// is compared as the int64 or the exact decimal it holds. The result is
+// nil when the value is null: a nil pointer, interface or map, or a
+// driver.Valuer with a nil value such as an invalid sql.NullString.
+func coerce(value reflect.Value) (interface{}, error) {
+	for i := 0; i < maxConversions; i++ {
+		if !value.IsValid() {
+			return nil, nil
+		}
+		switch value.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map:
+			if value.IsNil() {
+				return nil, nil
+			}
+		}
+		if converter, ok := converters.Load(value.Type()); ok {
+			converted, err := converter.(Converter)(value.Interface())
+			if err != nil {
+				return nil, err
+			}
+			value = reflect.ValueOf(converted)
+			continue
+		}
+		if value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			value = value.Elem()
+			continue
+		}
+		switch value.Type() {
+		case timeType:
+			return value.Interface(), nil
+		case jsonNumberType:
+			if intValue, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
+				return intValue, nil
+			}
+			if decimal, err := parseDecimal(value.String()); err == nil {
+				return decimal, nil
+			}
+			return value.String(), nil
+		case bigIntType:
+			bigInt := value.Interface().(big.Int)
+			return new(big.Rat).SetInt(&bigInt), nil
+		case bigRatType:
+			bigRat := value.Interface().(big.Rat)
+			return new(big.Rat).Set(&bigRat), nil
+		}
+		if valuer, ok := implementation(value, valuerType); ok {
+			converted, err := valuer.(driver.Valuer).Value()
+			if err != nil {
+				return nil, err
+			}
+			value = reflect.ValueOf(converted)
+			continue
+		}
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return value.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return value.Uint(), nil
+		case reflect.Float32:
+			return float32(value.Float()), nil
+		case reflect.Float64:
+			return value.Float(), nil
+		case reflect.Bool:
+			return value.Bool(), nil
+		case reflect.String:
+			return value.String(), nil
+		}
+		if marshaler, ok := implementation(value, textMarshalerType); ok {
+			text, err := marshaler.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return string(text), nil
+		}
+		if stringer, ok := implementation(value, stringerType); ok {
+			return stringer.(fmt.Stringer).String(), nil
+		}
+		return fmt.Sprint(value.Interface()), nil
+	}
+	return nil, fmt.Errorf("%s doesn't convert to a comparable value", value.Type())
+}
+
+// implementation returns value as iface when its type or a pointer to it
+// implements iface, the latter on a copy when value isn't addressable.
+func implementation(value reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if value.Type().Implements(iface) {
+		return value.Interface(), true
+	}
+	if !reflect.PtrTo(value.Type()).Implements(iface) {
+		return nil, false
+	}
+	if value.CanAddr() {
+		return value.Addr().Interface(), true
+	}
+	pointer := reflect.New(value.Type())
+	pointer.Elem().Set(value)
+	return pointer.Interface(), true
+}
@@ -0,0 +1,140 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"math/big"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// comparison holds what a comparison needs besides the field value, parsed
+// once when the validator is created.
+type comparison struct {
+	operand *operand
+	set     *valueSet
+}
+
+func newComparison(attribute *structs.Attribute) *comparison {
+	cmp := &comparison{
+		operand: parseOperand(attribute.Value, attribute.Operator == operators.OperatorContainsRegexMatch),
+	}
+	switch attribute.Operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		cmp.set = newValueSet(attribute.Values)
+	}
+	return cmp
+}
+
+// buildComparisons walks the condition tree and builds the comparison of
+// every attribute.
+func buildComparisons(condition *structs.Condition, comparisons map[*structs.Attribute]*comparison) {
+	if condition == nil {
+		return
+	}
+	if condition.Attribute != nil {
+		comparisons[condition.Attribute] = newComparison(condition.Attribute)
+	}
+	for _, subCondition := range condition.Conditions {
+		buildComparisons(subCondition, comparisons)
+	}
+}
+
+// operand is a condition value together with its parsed forms. A parsed
+// operand is read only and can be shared, otherwise the forms are parsed on
+// every use.
+type operand struct {
+	text         string
+	parsed       bool
+	intValue     int64
+	intErr       error
+	uintValue    uint64
+	uintErr      error
+	floatValue   float64
+	floatErr     error
+	float32Value float64
+	float32Err   error
+	timeValue    time.Time
+	timeErr      error
+	decimalValue *big.Rat
+	decimalErr   error
+	pattern      *regexp.Regexp
+	hasPattern   bool
+}
+
+func newOperand(text string) *operand {
+	return &operand{text: text}
+}
+
+// parseOperand parses text in every form, and compiles it as well when it is
+// a pattern, the value of a |~ comparison.
+func parseOperand(text string, isPattern bool) *operand {
+	o := &operand{text: text, parsed: true}
+	o.intValue, o.intErr = strconv.ParseInt(text, 10, 64)
+	o.uintValue, o.uintErr = strconv.ParseUint(text, 10, 64)
+	o.floatValue, o.floatErr = strconv.ParseFloat(text, 64)
+	o.float32Value, o.float32Err = strconv.ParseFloat(text, 32)
+	o.timeValue, o.timeErr = time.Parse(time.RFC3339, text)
+	o.decimalValue, o.decimalErr = parseDecimal(text)
+	if isPattern {
+		o.pattern, _ = regexp.Compile(text)
+		o.hasPattern = true
+	}
+	return o
+}
+
+func (o *operand) int() (int64, error) {
+	if o.parsed {
+		return o.intValue, o.intErr
+	}
+	return strconv.ParseInt(o.text, 10, 64)
+}
+
+func (o *operand) uint() (uint64, error) {
+	if o.parsed {
+		return o.uintValue, o.uintErr
+	}
+	return strconv.ParseUint(o.text, 10, 64)
+}
+
+func (o *operand) float() (float64, error) {
+	if o.parsed {
+		return o.floatValue, o.floatErr
+	}
+	return strconv.ParseFloat(o.text, 64)
+}
+
+// float32 returns the operand rounded to a float32, the way a float32 field
+// holds it.
+func (o *operand) float32() (float64, error) {
+	if o.parsed {
+		return o.float32Value, o.float32Err
+	}
+	return strconv.ParseFloat(o.text, 32)
+}
+
+func (o *operand) time() (time.Time, error) {
+	if o.parsed {
+		return o.timeValue, o.timeErr
+	}
+	return time.Parse(time.RFC3339, o.text)
+}
+
+// decimal returns the operand parsed exactly, the decimal of a parsed operand
+// is shared and must not be modified.
+func (o *operand) decimal() (*big.Rat, error) {
+	if o.parsed {
+		return o.decimalValue, o.decimalErr
+	}
+	return parseDecimal(o.text)
+}
+
+// regexp returns the compiled operand, nil when it isn't a valid pattern.
+func (o *operand) regexp() *regexp.Regexp {
+	if o.hasPattern {
+		return o.pattern
+	}
+	pattern, _ := regexp.Compile(o.text)
+	return pattern
+}
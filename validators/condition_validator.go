@@ -4,10 +4,14 @@ import (
 	"github.com/ahmadrezamusthafa/deep-validator/common/utils"
 	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
 	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
 	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/struct-gen"
 	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"reflect"
 	"strings"
-	"time"
 )
 
 type ConditionValidator interface {
@@ -15,22 +19,81 @@ type ConditionValidator interface {
 	Validate(data interface{}) (isValid bool, err error)
 	ValidateObjects(attributeNames map[string]interface{}, data ...interface{}) (isValid bool, err error)
 	SetRemovePrefix(value bool) *Condition
+	SetNameStrategy(strategy namestrategies.NameStrategy) *Condition
+	SetNameTag(tagKey string) *Condition
+	SetNullPolicy(policy nullpolicies.NullPolicy) *Condition
+	SetNumericMode(mode numericmodes.NumericMode) *Condition
 	FilterSlice(data interface{}) (result interface{}, err error)
+	Query(data interface{}, base structs.BaseCondition) (result interface{}, err error)
+	Compile(rType reflect.Type) (plan *Plan, err error)
+	Explain(data interface{}) (trace *structs.Trace, err error)
+	ExplainObjects(attributeNames map[string]interface{}, data ...interface{}) (trace *structs.Trace, err error)
 	GetCondition() *structs.Condition
 }
 
 type Condition struct {
 	*structs.Condition
 	removePrefix bool
+	fieldNamer   utils.FieldNamer
+	fieldIndexes *fieldIndexCache
+	nullPolicy   nullpolicies.NullPolicy
+	numericMode  numericmodes.NumericMode
+	comparisons  map[*structs.Attribute]*comparison
+	scopes       []scope
+	trace        *structs.Trace
 }
 
 func NewConditionValidator(condition *structs.Condition) ConditionValidator {
+	comparisons := make(map[*structs.Attribute]*comparison)
+	buildComparisons(condition, comparisons)
 	return &Condition{
 		Condition:    condition,
 		removePrefix: false,
+		fieldNamer:   utils.GoFieldName,
+		fieldIndexes: newFieldIndexCache(utils.GoFieldName),
+		nullPolicy:   nullpolicies.False,
+		numericMode:  numericmodes.Float,
+		comparisons:  comparisons,
 	}
 }
 
+// child returns the validator of subCondition sharing the settings of c. When
+// c is explaining, the trace node of subCondition is added to its trace.
+func (c *Condition) child(subCondition *structs.Condition) *Condition {
+	con := *c
+	con.Condition = subCondition
+	if c.trace != nil {
+		con.trace = structs.NewTrace(subCondition)
+		c.trace.Conditions = append(c.trace.Conditions, con.trace)
+	}
+	return &con
+}
+
+// isDecided tells whether the left to right fold of a sequence is decided
+// before a term joined with operator: `||` can't change true and `&&` can't
+// change false.
+func isDecided(isValid bool, operator string) bool {
+	if operator == logicaloperators.LogicalOperatorOr {
+		return isValid
+	}
+	return !isValid
+}
+
+// skip records in the trace that subCondition wasn't evaluated because the
+// result of its sequence was already decided.
+func (c *Condition) skip(subCondition *structs.Condition) {
+	if c.trace != nil {
+		c.child(subCondition).trace.Skipped = true
+	}
+}
+
+func (c *Condition) getComparison() *comparison {
+	if cmp, ok := c.comparisons[c.Attribute]; ok {
+		return cmp
+	}
+	return newComparison(c.Attribute)
+}
+
 func (c *Condition) GetCondition() *structs.Condition {
 	return c.Condition
 }
@@ -62,8 +125,10 @@ func readAllAttributes(condition *structs.Condition, attrMap map[string]bool) {
 func (c *Condition) validateConditionAttribute(inputCondition structs.Condition) (isValid bool, err error) {
 	if len(c.Conditions) > 0 {
 		for i, subCondition := range c.Conditions {
-			con := Condition{Condition: subCondition}
-			isSubValid, err := con.validateConditionAttribute(inputCondition)
+			if i > 0 && isDecided(isValid, subCondition.Operator) {
+				continue
+			}
+			isSubValid, err := c.child(subCondition).validateConditionAttribute(inputCondition)
 			if err != nil {
 				return false, err
 			}
@@ -77,16 +142,46 @@ func (c *Condition) validateConditionAttribute(inputCondition structs.Condition)
 				}
 			}
 		}
+	} else if c.Attribute != nil && c.Attribute.Type == valuetypes.Field {
+		value, ok := findAttributeValue(&inputCondition, c.Attribute.Value)
+		if !ok {
+			return false, nil
+		}
+		attribute := *c.Attribute
+		attribute.Value, attribute.Type = value, ""
+		con := *c.Condition
+		con.Attribute, con.Negate = &attribute, false
+		isValid, _, err = c.child(&con).validateConditionValue("", inputCondition)
 	} else {
 		isValid, _, err = c.validateConditionValue("", inputCondition)
 	}
+	if c.Negate {
+		isValid = !isValid
+	}
 	return
 }
 
+// findAttributeValue returns the value of the first comparison on name in
+// condition, the value a field reference is compared against.
+func findAttributeValue(condition *structs.Condition, name string) (string, bool) {
+	if condition.Attribute != nil && condition.Attribute.Name == name && len(condition.Conditions) == 0 {
+		return condition.Attribute.Value, true
+	}
+	for _, subCondition := range condition.Conditions {
+		if value, ok := findAttributeValue(subCondition, name); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func (c *Condition) validateConditionValue(prefix string, condition structs.Condition) (isValid, isSkip bool, err error) {
 	isValid = true
 	if len(condition.Conditions) > 0 {
 		for i, subCondition := range condition.Conditions {
+			if i > 0 && isDecided(isValid, subCondition.Operator) {
+				continue
+			}
 			isSubValid, isSkip, err := c.validateConditionValue(prefix, *subCondition)
 			if err != nil {
 				return false, false, err
@@ -113,30 +208,85 @@ func (c *Condition) validateConditionValue(prefix string, condition structs.Cond
 			switch operator {
 			case operators.OperatorEqual:
 				isValid = strings.EqualFold(condition.Attribute.Value, c.Attribute.Value)
+			case operators.OperatorIn, operators.OperatorNotIn:
+				isValid = c.getComparison().set.containsFold(condition.Attribute.Value) == (operator == operators.OperatorIn)
+			case operators.OperatorExists, operators.OperatorIsNotNull:
+				// the attributes the input condition lacks are given an empty
+				// value, so only a value that isn't empty exists
+				isValid = condition.Attribute.Value != ""
+			case operators.OperatorIsNull:
+				isValid = condition.Attribute.Value == ""
 			default:
 				value := condition.Attribute.Value
 				secondValue := c.Attribute.Value
-				valueType := getValueType(c.Attribute.Value)
+				valueType := structgen.ValueType(c.Attribute.Value)
 
 				switch valueType {
 				case valuetypes.Date:
 					isValid = validateTime(utils.StringToTime(value), operator, utils.StringToTime(secondValue))
 				default:
-					isValid = validateNumeric(utils.StringToFloat64(value), operator, utils.StringToFloat64(secondValue))
+					if isValid, err = c.validateNumericText(condition.Attribute.Name, value, operator, secondValue); err != nil {
+						return false, false, err
+					}
 				}
 			}
 		} else {
 			return false, true, nil
 		}
 	}
+	if condition.Negate {
+		isValid = !isValid
+	}
 	return
 }
 
+// validateNumericText compares two values of conditions at path as numbers.
+// Under the Float numeric mode a value that isn't a number compares as 0, as
+// it always has. Under Decimal the values are compared exactly, an attribute
+// the input condition lacks doesn't match and a value that isn't a number
+// fails with a *TypeMismatchError.
+func (c *Condition) validateNumericText(path, value, operator, secondValue string) (bool, error) {
+	if c.numericMode != numericmodes.Decimal {
+		return validateNumeric(utils.StringToFloat64(value), operator, utils.StringToFloat64(secondValue)), nil
+	}
+	if value == "" {
+		return false, nil
+	}
+	first, err := parseDecimal(value)
+	if err != nil {
+		return false, &TypeMismatchError{Path: path, FieldType: reflect.TypeOf(value), Literal: value, Err: err}
+	}
+	second, err := parseDecimal(secondValue)
+	if err != nil {
+		return false, &TypeMismatchError{Path: path, FieldType: reflect.TypeOf(value), Literal: secondValue, Err: err}
+	}
+	return validateDecimal(first, operator, second), nil
+}
+
+// SetRemovePrefix retries attribute paths that can't be resolved without
+// their first segment, e.g. `TransactionUpdatedEvent.Status` is resolved as
+// `Status` when the data has no TransactionUpdatedEvent.
 func (c *Condition) SetRemovePrefix(value bool) *Condition {
 	c.removePrefix = value
 	return c
 }
 
+// SetNameStrategy sets which name of a struct field attribute names are
+// matched against, the Go field name by default.
+func (c *Condition) SetNameStrategy(strategy namestrategies.NameStrategy) *Condition {
+	c.fieldNamer = utils.NewFieldNamer(strategy, "")
+	c.fieldIndexes = newFieldIndexCache(c.fieldNamer)
+	return c
+}
+
+// SetNameTag matches attribute names against the tagKey struct tag of a field,
+// falling back to the Go field name for fields without that tag.
+func (c *Condition) SetNameTag(tagKey string) *Condition {
+	c.fieldNamer = utils.NewFieldNamer(namestrategies.Tag, tagKey)
+	c.fieldIndexes = newFieldIndexCache(c.fieldNamer)
+	return c
+}
+
 func setNonExistAttributeDefaultValue(condition *structs.Condition, referenceAttrMap, inputAttrMap map[string]bool) {
 	for attrName, _ := range referenceAttrMap {
 		if _, ok := inputAttrMap[attrName]; !ok {
@@ -151,36 +301,3 @@ func setNonExistAttributeDefaultValue(condition *structs.Condition, referenceAtt
 		}
 	}
 }
-
-func getValueType(value string) valuetypes.ValueType {
-	varType, indexVal, dotCount := valuetypes.Alphanumeric, 0, 0
-	for _, char := range value {
-		if char == ',' {
-			continue
-		}
-		if '0' <= char && char <= '9' {
-			if indexVal == 0 || (indexVal > 0 && dotCount == 1) {
-				varType = valuetypes.Numeric
-			}
-		} else if char == '.' {
-			if indexVal > 0 && varType == valuetypes.Numeric {
-				dotCount++
-				varType = valuetypes.Alphanumeric
-			}
-			if dotCount > 1 {
-				varType = valuetypes.Alphanumeric
-				break
-			}
-		} else {
-			varType = valuetypes.Alphanumeric
-			break
-		}
-		indexVal++
-	}
-	if varType == valuetypes.Alphanumeric {
-		if _, err := time.Parse(time.RFC3339, value); err == nil {
-			varType = valuetypes.Date
-		}
-	}
-	return varType
-}
@@ -0,0 +1,95 @@
+package validators
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// SetNumericMode sets how numbers are compared, numericmodes.Float by
+// default. Under numericmodes.Decimal every number is compared exactly.
+func (c *Condition) SetNumericMode(mode numericmodes.NumericMode) *Condition {
+	c.numericMode = mode
+	return c
+}
+
+// decimalPattern matches the decimals parseDecimal takes. The exponent has at
+// most three digits, so a value can't make a decimal of millions of digits.
+var decimalPattern = regexp.MustCompile(`^[+-]?(?:(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d{1,3})?|\d+/\d+)$`)
+
+// parseDecimal parses text exactly, as a decimal with an optional exponent,
+// e.g. `100.25` or `1e-3`, or as a fraction such as `1/3`.
+func parseDecimal(text string) (*big.Rat, error) {
+	if !decimalPattern.MatchString(text) {
+		return nil, fmt.Errorf("%q is not a decimal", text)
+	}
+	decimal, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a decimal", text)
+	}
+	return decimal, nil
+}
+
+// toDecimal converts a value converted by coerce to the decimal it is
+// compared as under the Decimal numeric mode. A float is the shortest
+// decimal that prints it, so a float64 holding 0.1 is exactly 1/10. A string
+// is a decimal when it parses as one, ok is false for the other values.
+func toDecimal(value interface{}) (decimal *big.Rat, ok bool) {
+	switch val := value.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(val), true
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(val)), true
+	case float32:
+		return floatToDecimal(float64(val), 32)
+	case float64:
+		return floatToDecimal(val, 64)
+	case string:
+		decimal, err := parseDecimal(val)
+		return decimal, err == nil
+	case *big.Rat:
+		return val, true
+	}
+	return nil, false
+}
+
+// floatToDecimal converts a float of bitSize bits, NaN and the infinities
+// aren't decimals.
+func floatToDecimal(value float64, bitSize int) (*big.Rat, bool) {
+	decimal, err := parseDecimal(strconv.FormatFloat(value, 'f', -1, bitSize))
+	return decimal, err == nil
+}
+
+// formatDecimal formats a decimal exactly, as a fraction when it has no
+// finite decimal expansion.
+func formatDecimal(decimal *big.Rat) string {
+	if decimal.IsInt() {
+		return decimal.Num().String()
+	}
+	denominator := new(big.Int).Set(decimal.Denom())
+	digits := 0
+	for _, factor := range []int64{2, 5} {
+		divisor, remainder := big.NewInt(factor), new(big.Int)
+		for count := 0; ; count++ {
+			quotient, modulus := new(big.Int).QuoRem(denominator, divisor, remainder)
+			if modulus.Sign() != 0 {
+				if count > digits {
+					digits = count
+				}
+				break
+			}
+			denominator = quotient
+		}
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		return decimal.RatString()
+	}
+	return decimal.FloatString(digits)
+}
+
+// validateDecimal compares two decimals with an ordering operator.
+func validateDecimal(first *big.Rat, operator string, second *big.Rat) bool {
+	return validateOrdered(int64(first.Cmp(second)), operator, 0)
+}
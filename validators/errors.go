@@ -0,0 +1,123 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
+	"reflect"
+)
+
+// The sentinel errors every error of the matching type is, so callers can
+// classify an error with errors.Is without knowing its type.
+var (
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrInvalidCondition = errors.New("invalid condition")
+	ErrTypeMismatch     = errors.New("type mismatch")
+	ErrUnknownAttribute = errors.New("unknown attribute")
+)
+
+// InvalidInputError reports data a validation can't run on: nil, empty or of
+// a type the operation doesn't take. Type is nil when the data is nil.
+type InvalidInputError struct {
+	Type     reflect.Type
+	Expected string
+	message  string
+}
+
+// newNilDataError reports data that is nil, or empty where it must not be.
+func newNilDataError(rType reflect.Type, expected, data string) *InvalidInputError {
+	return &InvalidInputError{
+		Type:     rType,
+		Expected: expected,
+		message:  fmt.Sprintf(errormessages.ErrorMessageInvalidData, data),
+	}
+}
+
+// newInputTypeError reports data that isn't of the expected type.
+func newInputTypeError(rType reflect.Type, expected string) *InvalidInputError {
+	return &InvalidInputError{
+		Type:     rType,
+		Expected: expected,
+		message:  fmt.Sprintf(errormessages.ErrorMessageInvalidType, expected),
+	}
+}
+
+// newMapKeyError reports a map whose keys aren't strings.
+func newMapKeyError(rType reflect.Type) *InvalidInputError {
+	return &InvalidInputError{
+		Type:     rType,
+		Expected: "map with string keys",
+		message:  errormessages.ErrorMessageUnableToCastObject,
+	}
+}
+
+func (e *InvalidInputError) Error() string {
+	return e.message
+}
+
+func (e *InvalidInputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// InvalidConditionError reports a condition that can't be evaluated, e.g. an
+// unknown quantifier or function, or no condition at all.
+type InvalidConditionError struct {
+	Reason string
+}
+
+// newInvalidParameterError reports a condition missing the parameter it needs.
+func newInvalidParameterError(parameter string) *InvalidConditionError {
+	return &InvalidConditionError{
+		Reason: fmt.Sprintf(errormessages.ErrorMessageInvalidParameter, parameter),
+	}
+}
+
+func (e *InvalidConditionError) Error() string {
+	return e.Reason
+}
+
+func (e *InvalidConditionError) Is(target error) bool {
+	return target == ErrInvalidCondition
+}
+
+// TypeMismatchError reports a condition value that can't be converted to the
+// type of the field it is compared with, e.g. `Total = abc` on an int field,
+// or a field value a converter or driver.Valuer fails on. Err is the error of
+// the conversion.
+type TypeMismatchError struct {
+	Path      string
+	FieldType reflect.Type
+	Literal   string
+	Err       error
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("cannot compare %s of type %s with %q: %v", e.Path, e.FieldType, e.Literal, e.Err)
+}
+
+func (e *TypeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TypeMismatchError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+// UnknownAttributeError reports an attribute path that can't be resolved in
+// the data, or whose value is nil when IsNull is set. It is returned under
+// the Error null policy.
+type UnknownAttributeError struct {
+	Path   string
+	IsNull bool
+}
+
+func (e *UnknownAttributeError) Error() string {
+	if e.IsNull {
+		return fmt.Sprintf("attribute %s is null", e.Path)
+	}
+	return fmt.Sprintf("unknown attribute %s", e.Path)
+}
+
+func (e *UnknownAttributeError) Is(target error) bool {
+	return target == ErrUnknownAttribute
+}
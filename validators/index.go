@@ -0,0 +1,312 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/common/utils"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/name-strategies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Index narrows down which of many conditions some data can satisfy. For
+// every condition that is an and of comparisons, one `=` or `in` comparison it
+// can't be satisfied without is picked and its values indexed by attribute
+// path. Looking data up resolves each indexed path once and returns the
+// conditions whose picked comparison can hold, plus every condition without
+// one. The candidates are a superset of the satisfied conditions, they still
+// have to be validated.
+type Index struct {
+	resolver    *Condition
+	size        int
+	paths       []string
+	values      map[string]*valueIndex
+	unindexed   []int
+	nullPolicy  nullpolicies.NullPolicy
+	numericMode numericmodes.NumericMode
+}
+
+// NewIndex indexes conditions, candidates are reported by their position.
+func NewIndex(conditions []*structs.Condition) *Index {
+	x := &Index{
+		resolver:    NewConditionValidator(&structs.Condition{}).(*Condition),
+		size:        len(conditions),
+		values:      make(map[string]*valueIndex),
+		nullPolicy:  nullpolicies.False,
+		numericMode: numericmodes.Float,
+	}
+	for i, condition := range conditions {
+		attribute := discriminator(condition)
+		if attribute == nil {
+			x.unindexed = append(x.unindexed, i)
+			continue
+		}
+		values, ok := x.values[attribute.Name]
+		if !ok {
+			values = newValueIndex()
+			x.values[attribute.Name] = values
+			x.paths = append(x.paths, attribute.Name)
+		}
+		if attribute.Operator == operators.OperatorIn {
+			for _, value := range attribute.Values {
+				values.add(value, i)
+			}
+		} else {
+			values.add(attribute.Value, i)
+		}
+	}
+	return x
+}
+
+// SetRemovePrefix sets the prefix removal used to resolve the indexed paths,
+// it has to match the one of the validators of the conditions.
+func (x *Index) SetRemovePrefix(value bool) *Index {
+	x.resolver.SetRemovePrefix(value)
+	return x
+}
+
+// SetNameStrategy sets the name strategy used to resolve the indexed paths.
+func (x *Index) SetNameStrategy(strategy namestrategies.NameStrategy) *Index {
+	x.resolver.SetNameStrategy(strategy)
+	return x
+}
+
+// SetNameTag sets the name tag used to resolve the indexed paths.
+func (x *Index) SetNameTag(tagKey string) *Index {
+	x.resolver.SetNameTag(tagKey)
+	return x
+}
+
+// SetNullPolicy sets the null policy of the validators of the conditions, a
+// null indexed path is looked up as its zero value under ZeroValue and gives
+// every condition indexed under it under Error.
+func (x *Index) SetNullPolicy(policy nullpolicies.NullPolicy) *Index {
+	x.nullPolicy = policy
+	return x
+}
+
+// SetNumericMode sets the numeric mode of the validators of the conditions,
+// under Decimal a number is also looked up as the exact decimal it is.
+func (x *Index) SetNumericMode(mode numericmodes.NumericMode) *Index {
+	x.numericMode = mode
+	return x
+}
+
+// Candidates returns the positions of the conditions data may satisfy in
+// ascending order. Data the validators would reject, e.g. nil, an empty map
+// or a value a converter fails on, gives every condition so their errors
+// aren't lost.
+func (x *Index) Candidates(data interface{}) []int {
+	if data == nil {
+		return x.all()
+	}
+	rValue, status := indirect(reflect.ValueOf(data))
+	if status != pathFound {
+		return x.all()
+	}
+	switch rValue.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if rValue.Type().Key().Kind() != reflect.String || rValue.Len() == 0 {
+			return x.all()
+		}
+	default:
+		return x.all()
+	}
+
+	candidates := make([]int, len(x.unindexed), len(x.unindexed)+len(x.paths))
+	copy(candidates, x.unindexed)
+	for _, path := range x.paths {
+		value, status := x.resolver.resolve(rValue, path)
+		var key interface{}
+		if status == pathFound {
+			var err error
+			if key, err = coerce(value); err != nil {
+				return x.all()
+			}
+		}
+		if key != nil {
+			candidates = x.lookup(candidates, path, key)
+			continue
+		}
+		switch x.nullPolicy {
+		case nullpolicies.ZeroValue:
+			keys, err := zeroKeys(value)
+			if err != nil {
+				return x.all()
+			}
+			for _, key := range keys {
+				candidates = x.lookup(candidates, path, key)
+			}
+		case nullpolicies.Error:
+			candidates = append(candidates, x.values[path].conditions...)
+		}
+	}
+	sort.Ints(candidates)
+	unique := candidates[:0]
+	for _, position := range candidates {
+		if len(unique) == 0 || unique[len(unique)-1] != position {
+			unique = append(unique, position)
+		}
+	}
+	return unique
+}
+
+// lookup appends the positions indexed under path for key to candidates.
+func (x *Index) lookup(candidates []int, path string, key interface{}) []int {
+	values := x.values[path]
+	candidates = append(candidates, values.lookup(key)...)
+	if x.numericMode == numericmodes.Decimal {
+		if decimal, ok := toDecimal(key); ok {
+			candidates = append(candidates, values.positions[decimalKey(decimal.RatString())]...)
+		}
+	}
+	return candidates
+}
+
+// zeroKeys returns the values a null field is compared as under the ZeroValue
+// null policy: the zero value of the type a nil pointer points to, otherwise
+// any of the zero values zeroValue picks from.
+func zeroKeys(value reflect.Value) ([]interface{}, error) {
+	if value.IsValid() && value.Kind() == reflect.Ptr {
+		key, err := coerce(reflect.Zero(value.Type().Elem()))
+		if err != nil || key == nil {
+			return nil, err
+		}
+		return []interface{}{key}, nil
+	}
+	return []interface{}{int64(0), float64(0), time.Time{}, false, ""}, nil
+}
+
+func (x *Index) all() []int {
+	candidates := make([]int, x.size)
+	for i := range candidates {
+		candidates[i] = i
+	}
+	return candidates
+}
+
+// discriminator returns an `=` or `in` comparison on the data itself that
+// condition can't be satisfied without, the one with the fewest values when
+// there are several. Conditions containing an or have none.
+func discriminator(condition *structs.Condition) *structs.Attribute {
+	var best *structs.Attribute
+	var visit func(condition *structs.Condition) bool
+	visit = func(condition *structs.Condition) bool {
+		if condition == nil || condition.Negate || condition.Quantifier != "" {
+			return true
+		}
+		if len(condition.Conditions) == 0 {
+			if isIndexable(condition.Attribute) && (best == nil || valueCount(condition.Attribute) < valueCount(best)) {
+				best = condition.Attribute
+			}
+			return true
+		}
+		for i, subCondition := range condition.Conditions {
+			if i > 0 && subCondition.Operator == logicaloperators.LogicalOperatorOr {
+				return false
+			}
+		}
+		for _, subCondition := range condition.Conditions {
+			if !visit(subCondition) {
+				return false
+			}
+		}
+		return true
+	}
+	if !visit(condition) {
+		return nil
+	}
+	return best
+}
+
+func isIndexable(attribute *structs.Attribute) bool {
+	if attribute == nil || attribute.Name == "" || attribute.Function != "" || attribute.Type == valuetypes.Field {
+		return false
+	}
+	return attribute.Operator == operators.OperatorEqual || attribute.Operator == operators.OperatorIn
+}
+
+func valueCount(attribute *structs.Attribute) int {
+	if attribute.Operator == operators.OperatorIn {
+		return len(attribute.Values)
+	}
+	return 1
+}
+
+// timeKey keeps times apart from integers in the keys of a valueIndex.
+type timeKey int64
+
+// decimalKey keeps decimals, keyed by their exact fraction, apart from
+// strings in the keys of a valueIndex.
+type decimalKey string
+
+// valueIndex maps the values of indexed comparisons to the positions of their
+// conditions. A value is added under every type a field can be compared as,
+// like the members of a valueSet.
+type valueIndex struct {
+	positions  map[interface{}][]int
+	conditions []int
+}
+
+func newValueIndex() *valueIndex {
+	return &valueIndex{
+		positions: make(map[interface{}][]int),
+	}
+}
+
+func (v *valueIndex) add(value string, position int) {
+	if len(v.conditions) == 0 || v.conditions[len(v.conditions)-1] != position {
+		v.conditions = append(v.conditions, position)
+	}
+	v.put(value, position)
+	v.put(utils.StringToBool(value), position)
+	if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+		v.put(intValue, position)
+	}
+	if uintValue, err := strconv.ParseUint(value, 10, 64); err == nil {
+		v.put(uintValue, position)
+	}
+	if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+		v.put(floatValue, position)
+	}
+	if floatValue, err := strconv.ParseFloat(value, 32); err == nil {
+		v.put(float32(floatValue), position)
+	}
+	if timeValue, err := time.Parse(time.RFC3339, value); err == nil {
+		v.put(timeKey(timeValue.UnixNano()), position)
+	}
+	if decimal, err := parseDecimal(value); err == nil {
+		v.put(decimalKey(decimal.RatString()), position)
+	}
+}
+
+// put adds position under key once, positions are added in ascending order.
+func (v *valueIndex) put(key interface{}, position int) {
+	positions := v.positions[key]
+	if len(positions) > 0 && positions[len(positions)-1] == position {
+		return
+	}
+	v.positions[key] = append(positions, position)
+}
+
+// lookup returns the positions indexed under value, a value converted the way
+// coerce converts field values.
+func (v *valueIndex) lookup(value interface{}) []int {
+	switch val := value.(type) {
+	case int64, uint64, float32, float64, bool, string:
+		return v.positions[val]
+	case time.Time:
+		return v.positions[timeKey(val.UnixNano())]
+	case *big.Rat:
+		return v.positions[decimalKey(val.RatString())]
+	}
+	return nil
+}
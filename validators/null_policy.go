@@ -0,0 +1,152 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/functions"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"reflect"
+	"time"
+)
+
+// truth is the result of a condition in three valued logic. Only comparisons
+// of a null under the Unknown null policy are unknown, with the other
+// policies every result is true or false.
+type truth int8
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func toTruth(value bool) truth {
+	if value {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (t truth) not() truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+// join folds next, joined with operator, into t: `||` is true when either
+// side is, `&&` false when either side is, and otherwise an unknown side
+// makes the result unknown.
+func (t truth) join(operator string, next truth) truth {
+	decisive, other := truthFalse, truthTrue
+	if operator == logicaloperators.LogicalOperatorOr {
+		decisive, other = truthTrue, truthFalse
+	}
+	switch {
+	case t == decisive || next == decisive:
+		return decisive
+	case t == truthUnknown || next == truthUnknown:
+		return truthUnknown
+	}
+	return other
+}
+
+// isDecided is isDecided in three valued logic, an unknown result is decided
+// by neither operator.
+func (t truth) isDecided(operator string) bool {
+	return t != truthUnknown && isDecided(t == truthTrue, operator)
+}
+
+// SetNullPolicy sets what comparisons give when their field is nil or can't be
+// resolved, nullpolicies.False by default. exists, is null and is not null
+// test for those and don't use the policy.
+func (c *Condition) SetNullPolicy(policy nullpolicies.NullPolicy) *Condition {
+	c.nullPolicy = policy
+	return c
+}
+
+// validateNullPredicate evaluates exists, true when the last segment of the
+// path is found even if its value is nil, and is null / is not null, where
+// a missing path is null, and so is a value coerce converts to nil, e.g. an
+// invalid sql.NullString. A value that fails to convert isn't null.
+func (c *Condition) validateNullPredicate(value reflect.Value, status pathStatus) truth {
+	if status != pathFound {
+		c.traceMissing()
+		return toTruth(c.Attribute.Operator == operators.OperatorIsNull)
+	}
+	value, status = indirect(value)
+	if status == pathFound {
+		c.traceValues(value.Interface(), nil)
+		if converted, err := coerce(value); err == nil && converted == nil {
+			status = pathNull
+		}
+	}
+	switch c.Attribute.Operator {
+	case operators.OperatorIsNull:
+		return toTruth(status != pathFound)
+	case operators.OperatorIsNotNull:
+		return toTruth(status == pathFound)
+	}
+	return truthTrue
+}
+
+// nullResult is the result of a comparison of a null under the null policies
+// other than ZeroValue. path is the path that couldn't be resolved, isNull
+// tells it was found with a nil value.
+func (c *Condition) nullResult(path string, isNull bool) (truth, error) {
+	switch c.nullPolicy {
+	case nullpolicies.Unknown:
+		return truthUnknown, nil
+	case nullpolicies.Error:
+		return truthFalse, &UnknownAttributeError{Path: path, IsNull: isNull}
+	}
+	return truthFalse, nil
+}
+
+// zeroValue is what a null field is compared as under the ZeroValue policy:
+// the zero value of the type a nil pointer points to, or, when the field has
+// no type, of the type the condition value parses as. Its length is 0.
+func (c *Condition) zeroValue(value reflect.Value, conditionValue *operand) reflect.Value {
+	if value.Kind() == reflect.Ptr {
+		return reflect.Zero(value.Type().Elem())
+	}
+	if c.Attribute.Function == functions.FunctionLen {
+		return reflect.ValueOf("")
+	}
+	if len(c.Attribute.Values) > 0 {
+		conditionValue = newOperand(c.Attribute.Values[0])
+	}
+	if _, err := conditionValue.int(); err == nil {
+		return reflect.ValueOf(int64(0))
+	}
+	if _, err := conditionValue.float(); err == nil {
+		return reflect.ValueOf(float64(0))
+	}
+	if _, err := conditionValue.time(); err == nil {
+		return reflect.ValueOf(time.Time{})
+	}
+	switch conditionValue.text {
+	case "t", "true", "f", "false":
+		return reflect.ValueOf(false)
+	}
+	return reflect.ValueOf("")
+}
+
+// zeroText is the text a null field reference is compared as under the
+// ZeroValue policy, the zero value of the type of the field it is compared
+// with.
+func zeroText(value reflect.Value) string {
+	switch {
+	case value.Kind() == reflect.Ptr:
+		value = reflect.Zero(value.Type().Elem())
+	case !value.IsValid() || value.Kind() == reflect.Interface:
+		return ""
+	default:
+		value = reflect.Zero(value.Type())
+	}
+	text, _ := formatValue(value)
+	return text
+}
@@ -1,13 +1,16 @@
 package validators
 
 import (
-	"errors"
 	"fmt"
 	"github.com/ahmadrezamusthafa/deep-validator/common/utils"
-	errormessages "github.com/ahmadrezamusthafa/deep-validator/consts/error-messages"
-	"github.com/ahmadrezamusthafa/deep-validator/consts/logical-operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/functions"
 	"github.com/ahmadrezamusthafa/deep-validator/consts/operators"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/numeric-modes"
 	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -17,35 +20,109 @@ import (
 
 func (c *Condition) Validate(data interface{}) (isValid bool, err error) {
 	if data == nil {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+		return false, newNilDataError(nil, "struct", "nil")
 	}
 	rType := reflect.TypeOf(data)
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
 	switch rType.Kind() {
 	case reflect.Struct, reflect.Map:
-		isValid, _, err := c.validateAttribute(rType, data)
-		return isValid, err
+		result, _, err := c.validateAttribute(rType, data)
+		return result == truthTrue, err
 	default:
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidType, "struct")
+		return false, newInputTypeError(reflect.TypeOf(data), "struct")
 	}
 }
 
+// Explain validates data like Validate and returns the trace of the
+// evaluation of every condition node.
+func (c *Condition) Explain(data interface{}) (trace *structs.Trace, err error) {
+	con := c.explaining()
+	_, err = con.Validate(data)
+	return con.trace, err
+}
+
+// ExplainObjects validates data like ValidateObjects and returns the trace of
+// the evaluation of every condition node.
+func (c *Condition) ExplainObjects(attributeNames map[string]interface{}, data ...interface{}) (trace *structs.Trace, err error) {
+	con := c.explaining()
+	_, err = con.ValidateObjects(attributeNames, data...)
+	return con.trace, err
+}
+
+func (c *Condition) explaining() *Condition {
+	con := *c
+	con.trace = structs.NewTrace(c.Condition)
+	return &con
+}
+
 func (c *Condition) ValidateObjects(attributeNames map[string]interface{}, data ...interface{}) (isValid bool, err error) {
 	if data == nil {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+		return false, newNilDataError(nil, "slice", "nil")
 	}
 	rType := reflect.TypeOf(data)
 	switch rType.Kind() {
 	case reflect.Slice:
-		dataMap := utils.StructsToMap(attributeNames, data)
-		return c.Validate(dataMap)
+		return c.Validate(c.objectsToMap(attributeNames, data))
 	default:
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidType, "slice")
+		return false, newInputTypeError(rType, "slice")
+	}
+}
+
+// objectsToMap merges the objects into one map holding every attribute by its
+// bare name, the way a struct promotes the fields of embedded structs. The
+// first segment of every dotted attribute path is kept as well, and an object
+// is also reachable by its type name, so `Payload.Status` and
+// `TransactionUpdatedEvent.Payload.Status` can both be resolved.
+func (c *Condition) objectsToMap(attributeNames map[string]interface{}, data []interface{}) map[string]interface{} {
+	keys := make(map[string]interface{}, len(attributeNames))
+	for name := range attributeNames {
+		keys[name] = nil
+		if i := strings.IndexByte(name, '.'); i > 0 {
+			keys[name[:i]] = nil
+			if c.removePrefix {
+				name = name[i+1:]
+				keys[name] = nil
+				if i := strings.IndexByte(name, '.'); i > 0 {
+					keys[name[:i]] = nil
+				}
+			}
+		}
+	}
+	dataMap := utils.StructsToMapWithNamer(keys, data, c.fieldNamer)
+	for _, object := range flattenObjects(data, nil) {
+		rType := reflect.TypeOf(object)
+		if rType == nil {
+			continue
+		}
+		if rType.Kind() == reflect.Ptr {
+			rType = rType.Elem()
+		}
+		if _, ok := keys[rType.Name()]; !ok {
+			continue
+		}
+		if _, ok := dataMap[rType.Name()]; !ok {
+			dataMap[rType.Name()] = object
+		}
 	}
+	return dataMap
+}
+
+func flattenObjects(data []interface{}, objects []interface{}) []interface{} {
+	for _, object := range data {
+		if nested, ok := object.([]interface{}); ok {
+			objects = flattenObjects(nested, objects)
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects
 }
 
 func (c *Condition) FilterSlice(data interface{}) (result interface{}, err error) {
 	if data == nil {
-		return result, fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+		return result, newNilDataError(nil, "slice", "nil")
 	}
 	rType := reflect.TypeOf(data)
 	switch rType.Kind() {
@@ -65,7 +142,7 @@ func (c *Condition) FilterSlice(data interface{}) (result interface{}, err error
 		result = rSlice.Interface()
 		return
 	default:
-		return result, fmt.Errorf(errormessages.ErrorMessageInvalidType, "slice")
+		return result, newInputTypeError(rType, "slice")
 	}
 }
 
@@ -73,16 +150,16 @@ func (c *Condition) prepareDataFromSlice(data interface{}) (interface{}, error)
 	var preparedData interface{}
 	rValue := reflect.ValueOf(data)
 	if rValue.Type().Kind() != reflect.Slice {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidType, "slice")
+		return false, newInputTypeError(rValue.Type(), "slice")
 	}
 	if rValue.Len() == 0 {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "empty slice")
+		return false, newNilDataError(rValue.Type(), "non-empty slice", "empty slice")
 	}
 
 	firstValue := rValue.Index(0).Interface()
 	rFirstValue := reflect.ValueOf(firstValue)
 	if firstValue == nil {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+		return false, newNilDataError(nil, "struct", "nil")
 	}
 	switch rFirstValue.Type().Kind() {
 	case reflect.Struct:
@@ -91,7 +168,7 @@ func (c *Condition) prepareDataFromSlice(data interface{}) (interface{}, error)
 		length := rFirstValue.Len()
 		switch length {
 		case 0:
-			return false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "empty slice")
+			return false, newNilDataError(rFirstValue.Type(), "non-empty slice", "empty slice")
 		case 1:
 			preparedData = rFirstValue.Index(0).Interface()
 		default:
@@ -107,207 +184,393 @@ func (c *Condition) prepareDataFromSlice(data interface{}) (interface{}, error)
 	return preparedData, nil
 }
 
-func (c *Condition) validateAttribute(rType reflect.Type, data interface{}) (isValid, isSkip bool, err error) {
-	if len(c.Conditions) > 0 {
-		for i, subCondition := range c.Conditions {
-			con := Condition{Condition: subCondition}
-			isSubValid, isSkip, err := con.validateAttribute(rType, data)
-			if err != nil {
-				return false, false, err
-			}
-			if isSkip {
-				continue
-			}
-			if i == 0 {
-				isValid = isSubValid
-			} else {
-				if subCondition.Operator == logicaloperators.LogicalOperatorOr {
-					isValid = isValid || isSubValid
-				} else {
-					isValid = isValid && isSubValid
-				}
-			}
+func (c *Condition) validateAttribute(rType reflect.Type, data interface{}) (result truth, isSkip bool, err error) {
+	if c.Quantifier != "" {
+		result, err = c.validateQuantifier(rType, data)
+		if err != nil {
+			return truthFalse, false, err
+		}
+	} else if len(c.Conditions) > 0 {
+		result, err = c.validateConditions(rType, data)
+		if err != nil {
+			return truthFalse, false, err
 		}
 	} else {
 		switch rType.Kind() {
 		case reflect.Map:
-			if value, ok := data.(map[string]interface{}); ok {
-				isValid, isSkip, err = c.validateMapValue(value)
-			} else {
-				return false, false, errors.New(errormessages.ErrorMessageUnableToCastObject)
-			}
+			result, isSkip, err = c.validateMapValue(data)
 		default:
-			isValid, err = c.validateStructValue("", data)
+			result, err = c.validateStructValue(data)
 		}
 	}
+	if c.Negate && !isSkip {
+		result = result.not()
+	}
+	if c.trace != nil {
+		c.trace.Result, c.trace.Unknown, c.trace.Skipped = result == truthTrue, result == truthUnknown, isSkip
+	}
 	return
 }
 
-func (c *Condition) validateStructValue(prefix string, data interface{}) (isValid bool, err error) {
-	rValue := reflect.ValueOf(data)
-	if rValue.Type().Kind() != reflect.Struct {
-		return false, fmt.Errorf(errormessages.ErrorMessageInvalidType, "struct")
-	}
-	for i := 0; i < rValue.NumField(); i++ {
-		field := rValue.Field(i)
-		typeField := rValue.Type().Field(i)
-		tag := typeField.Name
-		tag = prefix + tag
-
-		if tag == c.Attribute.Name {
-			var conditionValue interface{}
-			validationType := valuetypes.Numeric
-			value := field.Interface()
-			operator := c.Attribute.Operator
-
-			if field.Kind() == reflect.Ptr && field.IsNil() {
-				return false, nil
-			}
-
-			switch value.(type) {
-			case int, int64:
-				value = utils.InterfaceToInt64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case *int, *int64:
-				value = utils.InterfacePtrToInt64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case float32, float64:
-				value = utils.InterfaceToFloat64(value)
-				conditionValue, err = strconv.ParseFloat(c.Attribute.Value, 64)
-			case *float32, *float64:
-				value = utils.InterfacePtrToFloat64(value)
-				conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-			case time.Time:
-				validationType = valuetypes.Date
-				conditionValue, err = time.Parse(time.RFC3339, c.Attribute.Value)
-			case *time.Time:
-				validationType = valuetypes.Date
-				res, ok := value.(*time.Time)
-				if ok {
-					value = *res
-				}
-				conditionValue, err = time.Parse(time.RFC3339, c.Attribute.Value)
-			case bool:
-				validationType = valuetypes.Alphanumeric
-				conditionValue = utils.StringToBool(c.Attribute.Value)
-			case *string:
-				validationType = valuetypes.Alphanumeric
-				value = utils.InterfacePtrToString(value)
-				conditionValue = c.Attribute.Value
-			default:
-				validationType = valuetypes.Alphanumeric
-				conditionValue = c.Attribute.Value
-			}
-			if err != nil {
-				return false, err
-			}
-
-			switch operator {
-			case operators.OperatorEqual:
-				isValid = value == conditionValue
-			case operators.OperatorNotEqual:
-				isValid = value != conditionValue
-			case operators.OperatorContains:
-				isValid = validateAlphanumericContains(value, conditionValue)
-			case operators.OperatorContainsRegexMatch:
-				isValid = validateAlphanumericRegexContains(value, conditionValue)
-			default:
-				switch validationType {
-				case valuetypes.Date:
-					isValid = validateTime(value, operator, conditionValue)
-				default:
-					isValid = validateNumeric(value, operator, conditionValue)
-				}
-			}
+// validateConditions folds the results of the sub conditions left to right.
+// A sub condition that can't change the result, an `||` after true or an
+// `&&` after false, isn't evaluated.
+func (c *Condition) validateConditions(rType reflect.Type, data interface{}) (result truth, err error) {
+	for i, subCondition := range c.Conditions {
+		if i > 0 && result.isDecided(subCondition.Operator) {
+			c.skip(subCondition)
+			continue
+		}
+		subResult, isSkip, err := c.child(subCondition).validateAttribute(rType, data)
+		if err != nil {
+			return truthFalse, err
+		}
+		if isSkip {
+			continue
+		}
+		if i == 0 {
+			result = subResult
+		} else {
+			result = result.join(subCondition.Operator, subResult)
 		}
 	}
 	return
 }
 
-func (c *Condition) validateMapValue(data map[string]interface{}) (isValid, isSkip bool, err error) {
-	if data == nil || len(data) == 0 {
-		return false, false, fmt.Errorf(errormessages.ErrorMessageInvalidData, "nil")
+// validateQuantifier evaluates the conditions of the quantifier against every
+// element of its collection. A collection that is missing or nil is null, one
+// that isn't a slice never matches and an empty one matches all and none but
+// not any.
+func (c *Condition) validateQuantifier(rType reflect.Type, data interface{}) (result truth, err error) {
+	switch c.Quantifier {
+	case quantifiers.QuantifierAny, quantifiers.QuantifierAll, quantifiers.QuantifierNone:
+	default:
+		return truthFalse, newInvalidParameterError("quantifier any, all or none")
+	}
+	collection, status := c.resolve(reflect.ValueOf(data), c.Collection)
+	if status == pathFound {
+		collection, status = indirect(collection)
 	}
-	isSkip = true
-	if v, ok := data[c.Attribute.Name]; ok {
-		isSkip = false
-		isValid, err = c.validateMap(c.Attribute.Name, v)
+	if status != pathFound {
+		if c.nullPolicy == nullpolicies.ZeroValue {
+			return toTruth(c.Quantifier != quantifiers.QuantifierAny), nil
+		}
+		return c.nullResult(c.Collection, status == pathNull)
+	}
+	if collection.Kind() != reflect.Slice && collection.Kind() != reflect.Array {
+		return truthFalse, nil
+	}
+	isUnknown := false
+	for i := 0; i < collection.Len(); i++ {
+		con := *c
+		con.scopes = append(c.scopes[:len(c.scopes):len(c.scopes)], scope{
+			path:    c.Collection,
+			element: collection.Index(i),
+		})
+		if c.trace != nil {
+			con.trace = &structs.Trace{}
+		}
+		elementResult, err := con.validateConditions(rType, data)
 		if err != nil {
-			return false, false, err
+			return truthFalse, err
+		}
+		if c.trace != nil {
+			c.trace.Elements = append(c.trace.Elements, &structs.ElementTrace{
+				Index:      i,
+				Result:     elementResult == truthTrue,
+				Unknown:    elementResult == truthUnknown,
+				Conditions: con.trace.Conditions,
+			})
+		}
+		switch {
+		case elementResult == truthUnknown:
+			isUnknown = true
+		case c.Quantifier == quantifiers.QuantifierAny && elementResult == truthTrue:
+			return truthTrue, nil
+		case c.Quantifier == quantifiers.QuantifierAll && elementResult == truthFalse,
+			c.Quantifier == quantifiers.QuantifierNone && elementResult == truthTrue:
+			return truthFalse, nil
 		}
-	} else {
-		return false, false, err
+	}
+	if isUnknown {
+		return truthUnknown, nil
+	}
+	return toTruth(c.Quantifier != quantifiers.QuantifierAny), nil
+}
+
+func (c *Condition) validateStructValue(data interface{}) (result truth, err error) {
+	rValue := reflect.ValueOf(data)
+	value, status := c.resolveAttribute(rValue)
+	return c.validateValue(value, status, c.reference(rValue))
+}
+
+func (c *Condition) validateMapValue(data interface{}) (result truth, isSkip bool, err error) {
+	rValue, status := indirect(reflect.ValueOf(data))
+	if status != pathFound {
+		return truthFalse, false, nil
+	}
+	if rValue.Type().Key().Kind() != reflect.String {
+		return truthFalse, false, newMapKeyError(rValue.Type())
+	}
+	if rValue.Len() == 0 {
+		return truthFalse, false, newNilDataError(rValue.Type(), "non-empty map", "nil")
+	}
+	value, status := c.resolveAttribute(rValue)
+	result, err = c.validateValue(value, status, c.reference(rValue))
+	if err != nil {
+		return truthFalse, false, err
 	}
 	return
 }
 
-func (c *Condition) validateMap(key string, value interface{}) (isValid bool, err error) {
-	if key == c.Attribute.Name {
-		var conditionValue interface{}
-		validationType := valuetypes.Numeric
-		operator := c.Attribute.Operator
-
-		if value == nil {
-			return false, nil
-		}
-
-		switch value.(type) {
-		case int, int64:
-			value = utils.InterfaceToInt64(value)
-			conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-		case *int, *int64:
-			value = utils.InterfacePtrToInt64(value)
-			conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-		case float32, float64:
-			value = utils.InterfaceToFloat64(value)
-			conditionValue, err = strconv.ParseFloat(c.Attribute.Value, 64)
-		case *float32, *float64:
-			value = utils.InterfacePtrToFloat64(value)
-			conditionValue, err = strconv.ParseInt(c.Attribute.Value, 10, 64)
-		case time.Time:
-			validationType = valuetypes.Date
-			conditionValue, err = time.Parse(time.RFC3339, c.Attribute.Value)
-		case *time.Time:
-			validationType = valuetypes.Date
-			res, ok := value.(*time.Time)
-			if ok {
-				value = *res
+// reference returns the resolver of the field the condition value refers to,
+// nil when the condition value is a literal.
+func (c *Condition) reference(data reflect.Value) func() (reflect.Value, pathStatus) {
+	if c.Attribute.Type != valuetypes.Field {
+		return nil
+	}
+	return func() (reflect.Value, pathStatus) {
+		return c.resolve(data, c.Attribute.Value)
+	}
+}
+
+// validateValue compares a resolved value, status tells whether it was found.
+// The null predicates test for a missing or nil value, the null policy decides
+// what the other comparisons of one give. The value is compared as coerce
+// converts it. A field reference is resolved as well and compared the way a
+// literal of the same text would be.
+func (c *Condition) validateValue(value reflect.Value, status pathStatus, reference func() (reflect.Value, pathStatus)) (result truth, err error) {
+	switch c.Attribute.Operator {
+	case operators.OperatorExists, operators.OperatorIsNull, operators.OperatorIsNotNull:
+		return c.validateNullPredicate(value, status), nil
+	}
+	var fieldValue interface{}
+	isNull := status != pathFound
+	if isNull {
+		c.traceMissing()
+	} else {
+		for value.Kind() == reflect.Interface && !value.IsNil() {
+			value = value.Elem()
+		}
+		isNull = (value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr) && value.IsNil()
+		if !isNull && c.Attribute.Function == "" {
+			if fieldValue, err = c.coerce(value); err != nil {
+				return truthFalse, err
+			}
+			isNull = fieldValue == nil
+		}
+	}
+	if isNull && c.nullPolicy != nullpolicies.ZeroValue {
+		return c.nullResult(c.Attribute.Name, status == pathFound)
+	}
+	conditionValue := c.getComparison().operand
+	if reference != nil {
+		text, ok := "", false
+		referenceValue, referenceStatus := reference()
+		if referenceStatus == pathFound {
+			text, ok = formatValue(referenceValue)
+		}
+		if !ok {
+			if c.nullPolicy != nullpolicies.ZeroValue {
+				return c.nullResult(c.Attribute.Value, referenceStatus == pathFound)
 			}
-			conditionValue, err = time.Parse(time.RFC3339, c.Attribute.Value)
-		case bool:
-			validationType = valuetypes.Alphanumeric
-			conditionValue = utils.StringToBool(c.Attribute.Value)
-		case *string:
-			validationType = valuetypes.Alphanumeric
-			value = utils.InterfacePtrToString(value)
-			conditionValue = c.Attribute.Value
+			text = zeroText(value)
+		}
+		conditionValue = newOperand(text)
+	}
+	if isNull {
+		value = c.zeroValue(value, conditionValue)
+		if c.Attribute.Function == "" {
+			if fieldValue, err = c.coerce(value); err != nil {
+				return truthFalse, err
+			}
+		}
+	}
+	c.traceValues(value.Interface(), nil)
+
+	var isValid bool
+	switch c.Attribute.Function {
+	case "":
+		isValid, err = c.compareValue(value.Type(), fieldValue, conditionValue)
+	case functions.FunctionLen:
+		value, status := indirect(value)
+		if status != pathFound {
+			return truthFalse, nil
+		}
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+			isValid, err = c.compareValue(value.Type(), int64(value.Len()), conditionValue)
+		}
+	default:
+		err = newInvalidParameterError("function len")
+	}
+	return toTruth(isValid), err
+}
+
+// coerce converts a field value with coerce, a conversion error is reported
+// as a TypeMismatchError of the attribute.
+func (c *Condition) coerce(value reflect.Value) (interface{}, error) {
+	converted, err := coerce(value)
+	if err != nil {
+		return nil, &TypeMismatchError{
+			Path:      c.Attribute.Name,
+			FieldType: value.Type(),
+			Literal:   c.Attribute.Value,
+			Err:       err,
+		}
+	}
+	return converted, nil
+}
+
+// compareValue compares a field value, converted by coerce, against the
+// condition value, parsing the condition value as the type the field value
+// was converted to. fieldType is the type of the field before the conversion.
+func (c *Condition) compareValue(fieldType reflect.Type, value interface{}, attributeValue *operand) (isValid bool, err error) {
+	var conditionValue interface{}
+	validationType := valuetypes.Numeric
+	operator := c.Attribute.Operator
+	if decimal, ok := c.decimalOf(value, attributeValue); ok {
+		value = decimal
+	}
+
+	switch operator {
+	case operators.OperatorIn, operators.OperatorNotIn:
+		c.traceValues(value, c.Attribute.Values)
+		return c.getComparison().set.contains(value) == (operator == operators.OperatorIn), nil
+	}
+
+	switch val := value.(type) {
+	case int64:
+		conditionValue, err = attributeValue.int()
+	case uint64:
+		conditionValue, err = attributeValue.uint()
+	case float32:
+		value = float64(val)
+		conditionValue, err = attributeValue.float32()
+	case float64:
+		conditionValue, err = attributeValue.float()
+	case *big.Rat:
+		conditionValue, err = attributeValue.decimal()
+	case time.Time:
+		validationType = valuetypes.Date
+		conditionValue, err = attributeValue.time()
+	case bool:
+		validationType = valuetypes.Alphanumeric
+		conditionValue = utils.StringToBool(attributeValue.text)
+	default:
+		validationType = valuetypes.Alphanumeric
+		conditionValue = attributeValue.text
+	}
+	if err != nil {
+		return false, &TypeMismatchError{
+			Path:      c.Attribute.Name,
+			FieldType: fieldType,
+			Literal:   attributeValue.text,
+			Err:       err,
+		}
+	}
+	c.traceValues(value, conditionValue)
+
+	switch operator {
+	case operators.OperatorEqual:
+		isValid = equalValues(value, conditionValue)
+	case operators.OperatorNotEqual:
+		isValid = !equalValues(value, conditionValue)
+	case operators.OperatorContains:
+		isValid = validateAlphanumericContains(value, conditionValue)
+	case operators.OperatorContainsRegexMatch:
+		if _, ok := conditionValue.(string); ok {
+			conditionValue = attributeValue.regexp()
+		}
+		isValid = validateAlphanumericRegexContains(value, conditionValue)
+	default:
+		switch validationType {
+		case valuetypes.Date:
+			isValid = validateTime(value, operator, conditionValue)
 		default:
-			validationType = valuetypes.Alphanumeric
-			conditionValue = c.Attribute.Value
+			isValid = validateNumeric(value, operator, conditionValue)
 		}
-		if err != nil {
-			return false, err
-		}
-
-		switch operator {
-		case operators.OperatorEqual:
-			isValid = value == conditionValue
-		case operators.OperatorNotEqual:
-			isValid = value != conditionValue
-		case operators.OperatorContains:
-			isValid = validateAlphanumericContains(value, conditionValue)
-		case operators.OperatorContainsRegexMatch:
-			isValid = validateAlphanumericRegexContains(value, conditionValue)
+	}
+	return
+}
+
+// decimalOf returns the decimal a field value, converted by coerce, is
+// compared as. A *big.Rat always is one. Under the Decimal numeric mode so is
+// every number, and a string holding a decimal when it is compared with a
+// decimal condition value, except by the text operators |= and |~. ok is
+// false when the value is compared as it is.
+func (c *Condition) decimalOf(value interface{}, attributeValue *operand) (decimal *big.Rat, ok bool) {
+	if decimal, ok := value.(*big.Rat); ok {
+		return decimal, true
+	}
+	if c.numericMode != numericmodes.Decimal {
+		return nil, false
+	}
+	if _, ok := value.(string); ok {
+		switch c.Attribute.Operator {
+		case operators.OperatorIn, operators.OperatorNotIn:
+		case operators.OperatorContains, operators.OperatorContainsRegexMatch:
+			return nil, false
 		default:
-			switch validationType {
-			case valuetypes.Date:
-				isValid = validateTime(value, operator, conditionValue)
-			default:
-				isValid = validateNumeric(value, operator, conditionValue)
+			if _, err := attributeValue.decimal(); err != nil {
+				return nil, false
 			}
 		}
 	}
-	return
+	return toDecimal(value)
+}
+
+// equalValues tells whether a field value equals the condition value it was
+// parsed for, decimals are equal when their values are.
+func equalValues(first, second interface{}) bool {
+	if firstDecimal, ok := first.(*big.Rat); ok {
+		secondDecimal, ok := second.(*big.Rat)
+		return ok && firstDecimal.Cmp(secondDecimal) == 0
+	}
+	return first == second
+}
+
+// traceMissing records in the trace that the attribute couldn't be resolved.
+func (c *Condition) traceMissing() {
+	if c.trace != nil && c.trace.Attribute != nil {
+		c.trace.Attribute.Missing = true
+	}
+}
+
+// traceValues records the compared values in the trace.
+func (c *Condition) traceValues(fieldValue, conditionValue interface{}) {
+	if c.trace != nil && c.trace.Attribute != nil {
+		c.trace.Attribute.FieldValue, c.trace.Attribute.ConditionValue = fieldValue, conditionValue
+	}
+}
+
+// formatValue formats a referenced field value, converted by coerce, as the
+// text of a literal. A null or a value that fails to convert can't be
+// formatted.
+func formatValue(value reflect.Value) (string, bool) {
+	converted, err := coerce(value)
+	if err != nil || converted == nil {
+		return "", false
+	}
+	switch val := converted.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339Nano), true
+	case int64:
+		return strconv.FormatInt(val, 10), true
+	case uint64:
+		return strconv.FormatUint(val, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), true
+	case *big.Rat:
+		return formatDecimal(val), true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	case string:
+		return val, true
+	}
+	return fmt.Sprint(converted), true
 }
 
 func validateAlphanumericContains(str interface{}, subStr interface{}) bool {
@@ -328,13 +591,12 @@ func validateAlphanumericRegexContains(str interface{}, pattern interface{}) boo
 	if !ok {
 		return false
 	}
-	patternStr, ok := pattern.(string)
-	if !ok {
+	patternRegexp, ok := pattern.(*regexp.Regexp)
+	if !ok || patternRegexp == nil {
 		return false
 	}
 
-	match, _ := regexp.MatchString(patternStr, input)
-	return match
+	return patternRegexp.MatchString(input)
 }
 
 func validateTime(firstVal interface{}, operator string, secondVal interface{}) bool {
@@ -359,32 +621,35 @@ func validateTime(firstVal interface{}, operator string, secondVal interface{})
 	}
 }
 
+// validateNumeric compares two numbers of the same type, int64, uint64,
+// float64 or *big.Rat, exactly.
 func validateNumeric(firstVal interface{}, operator string, secondVal interface{}) bool {
-	firstFloat, ok := firstVal.(float64)
-	if !ok {
-		firstInt, ok := firstVal.(int64)
-		if !ok {
-			return false
-		}
-		firstFloat = float64(firstInt)
-	}
-	secondFloat, ok := secondVal.(float64)
-	if !ok {
-		secondInt, ok := secondVal.(int64)
-		if !ok {
-			return false
-		}
-		secondFloat = float64(secondInt)
+	switch first := firstVal.(type) {
+	case *big.Rat:
+		second, ok := secondVal.(*big.Rat)
+		return ok && validateDecimal(first, operator, second)
+	case int64:
+		second, ok := secondVal.(int64)
+		return ok && validateOrdered(first, operator, second)
+	case uint64:
+		second, ok := secondVal.(uint64)
+		return ok && validateOrdered(first, operator, second)
+	case float64:
+		second, ok := secondVal.(float64)
+		return ok && validateOrdered(first, operator, second)
 	}
+	return false
+}
 
+func validateOrdered[T int64 | uint64 | float64](first T, operator string, second T) bool {
 	switch operator {
 	case operators.OperatorGreaterThan:
-		return firstFloat > secondFloat
+		return first > second
 	case operators.OperatorLessThan:
-		return firstFloat < secondFloat
+		return first < second
 	case operators.OperatorGreaterThanEqual:
-		return firstFloat >= secondFloat
+		return first >= second
 	default:
-		return firstFloat <= secondFloat
+		return first <= second
 	}
 }
@@ -0,0 +1,191 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/common/utils"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pathStatus tells how resolving an attribute path ended.
+type pathStatus int
+
+const (
+	// pathFound means the last segment of the path was found, its value may
+	// still be a nil pointer or interface.
+	pathFound pathStatus = iota
+	// pathNull means a nil pointer, interface or map was met before the last
+	// segment of the path.
+	pathNull
+	// pathMissing means a segment of the path doesn't exist in the data.
+	pathMissing
+)
+
+// fieldIndexCache caches, per struct type, the index of every field reachable
+// by name, promoted fields of embedded structs included.
+type fieldIndexCache struct {
+	fieldNamer utils.FieldNamer
+	indexes    sync.Map
+}
+
+func newFieldIndexCache(fieldNamer utils.FieldNamer) *fieldIndexCache {
+	return &fieldIndexCache{
+		fieldNamer: fieldNamer,
+	}
+}
+
+func (f *fieldIndexCache) get(rType reflect.Type) map[string][]int {
+	if indexes, ok := f.indexes.Load(rType); ok {
+		return indexes.(map[string][]int)
+	}
+	indexes := make(map[string][]int)
+	depths := make(map[string]int)
+	f.build(rType, nil, 0, indexes, depths, map[reflect.Type]bool{})
+	f.indexes.Store(rType, indexes)
+	return indexes
+}
+
+// build follows the Go promotion rule: a field at a shallower depth hides the
+// fields of the same name in embedded structs.
+func (f *fieldIndexCache) build(rType reflect.Type, parent []int, depth int, indexes map[string][]int, depths map[string]int, visited map[reflect.Type]bool) {
+	if visited[rType] {
+		return
+	}
+	visited[rType] = true
+	defer delete(visited, rType)
+
+	for i := 0; i < rType.NumField(); i++ {
+		typeField := rType.Field(i)
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		if name := f.fieldNamer(typeField); name != "" {
+			if d, ok := depths[name]; !ok || depth < d {
+				indexes[name] = index
+				depths[name] = depth
+			}
+		}
+		if typeField.Anonymous && typeField.PkgPath == "" {
+			embeddedType := typeField.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				f.build(embeddedType, index, depth+1, indexes, depths, visited)
+			}
+		}
+	}
+}
+
+// scope binds the collection path of a quantifier to the element being
+// evaluated, so paths starting with the collection path resolve against it.
+type scope struct {
+	path    string
+	element reflect.Value
+}
+
+// resolveAttribute resolves the attribute path of the condition in data.
+func (c *Condition) resolveAttribute(data reflect.Value) (reflect.Value, pathStatus) {
+	return c.resolve(data, c.Attribute.Name)
+}
+
+// resolve resolves path against the innermost quantifier element it starts
+// with, or else against data. When the prefix removal is enabled a path that
+// can't be resolved is retried without its first segment, so `Event.Status`
+// also matches a bare `Status`.
+func (c *Condition) resolve(data reflect.Value, path string) (reflect.Value, pathStatus) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		s := c.scopes[i]
+		if path == s.path {
+			return s.element, pathFound
+		}
+		if strings.HasPrefix(path, s.path) && path[len(s.path)] == '.' {
+			return c.resolvePath(s.element, path[len(s.path)+1:])
+		}
+	}
+	value, status := c.resolvePath(data, path)
+	if status == pathMissing && c.removePrefix {
+		if i := strings.IndexByte(path, '.'); i > 0 {
+			return c.resolvePath(data, path[i+1:])
+		}
+	}
+	return value, status
+}
+
+// resolvePath walks data along a dot separated path. Pointers and interfaces
+// are dereferenced, struct fields are looked up by the name the field namer
+// gives them, maps by string key and slices by index. Names containing dots
+// are supported by trying the longest matching prefix first.
+func (c *Condition) resolvePath(data reflect.Value, path string) (reflect.Value, pathStatus) {
+	data, status := indirect(data)
+	if status != pathFound {
+		return data, status
+	}
+	if value, status := c.lookupChild(data, path); status != pathMissing {
+		return value, status
+	}
+	for i := strings.LastIndexByte(path, '.'); i > 0; i = strings.LastIndexByte(path[:i], '.') {
+		value, status := c.lookupChild(data, path[:i])
+		switch status {
+		case pathMissing:
+			continue
+		case pathNull:
+			return value, status
+		}
+		return c.resolvePath(value, path[i+1:])
+	}
+	return reflect.Value{}, pathMissing
+}
+
+func (c *Condition) lookupChild(data reflect.Value, name string) (reflect.Value, pathStatus) {
+	switch data.Kind() {
+	case reflect.Struct:
+		index, ok := c.fieldIndexes.get(data.Type())[name]
+		if !ok {
+			return reflect.Value{}, pathMissing
+		}
+		for i, x := range index {
+			if i > 0 {
+				var status pathStatus
+				if data, status = indirect(data); status != pathFound {
+					return data, status
+				}
+			}
+			data = data.Field(x)
+		}
+		return data, pathFound
+	case reflect.Map:
+		if data.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, pathMissing
+		}
+		value := data.MapIndex(reflect.ValueOf(name).Convert(data.Type().Key()))
+		if !value.IsValid() {
+			return reflect.Value{}, pathMissing
+		}
+		return value, pathFound
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= data.Len() {
+			return reflect.Value{}, pathMissing
+		}
+		return data.Index(i), pathFound
+	}
+	return reflect.Value{}, pathMissing
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete
+// value, reporting pathNull when it meets a nil one.
+func indirect(value reflect.Value) (reflect.Value, pathStatus) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, pathNull
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Map && value.IsNil() {
+		return value, pathNull
+	}
+	return value, pathFound
+}
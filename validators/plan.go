@@ -0,0 +1,368 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/consts/functions"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/quantifiers"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/null-policies"
+	"github.com/ahmadrezamusthafa/deep-validator/enums/value-types"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"reflect"
+	"strings"
+)
+
+// Plan is a condition compiled for one struct or map type. Field indexes are
+// resolved, operands parsed and regexes compiled up front, so validating only
+// walks the data. A Plan is read only and safe for concurrent use.
+type Plan struct {
+	rType reflect.Type
+	root  evaluator
+}
+
+// planEnv is the data of one validation: the object and the current element
+// of every enclosing quantifier.
+type planEnv struct {
+	data     reflect.Value
+	elements []reflect.Value
+}
+
+type evaluator func(env *planEnv) (result truth, err error)
+
+type accessor func(env *planEnv) (reflect.Value, pathStatus)
+
+// planScope is the compile time counterpart of scope, depth is the index of
+// the element in planEnv.elements and rType its static type, nil when it's
+// only known at run time.
+type planScope struct {
+	path  string
+	depth int
+	rType reflect.Type
+}
+
+type planCompiler struct {
+	*Condition
+	rType  reflect.Type
+	isMap  bool
+	scopes []planScope
+}
+
+// Compile compiles condition for data of type rType with the default
+// settings of a validator.
+func Compile(condition *structs.Condition, rType reflect.Type) (*Plan, error) {
+	return NewConditionValidator(condition).Compile(rType)
+}
+
+// Compile compiles the condition for data of type rType, a struct or a map
+// with string keys, or a pointer to one. The plan keeps the prefix removal,
+// field name, null policy and numeric mode settings of c at the time of
+// compiling.
+func (c *Condition) Compile(rType reflect.Type) (*Plan, error) {
+	if c.Condition == nil {
+		return nil, &InvalidConditionError{Reason: "condition is nil"}
+	}
+	if rType == nil {
+		return nil, newInputTypeError(nil, "struct")
+	}
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	settings := *c
+	settings.trace = nil
+	compiler := &planCompiler{
+		Condition: &settings,
+		rType:     rType,
+	}
+	switch rType.Kind() {
+	case reflect.Struct:
+	case reflect.Map:
+		if rType.Key().Kind() != reflect.String {
+			return nil, newMapKeyError(rType)
+		}
+		compiler.isMap = true
+	default:
+		return nil, newInputTypeError(rType, "struct")
+	}
+	root, err := compiler.compile(c.Condition)
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{
+		rType: rType,
+		root:  root,
+	}, nil
+}
+
+// Type returns the type the plan was compiled for.
+func (p *Plan) Type() reflect.Type {
+	return p.rType
+}
+
+// Validate validates data, a value of the type of the plan or a pointer to
+// one, the way the validator it was compiled from would.
+func (p *Plan) Validate(data interface{}) (isValid bool, err error) {
+	if data == nil {
+		return false, newNilDataError(nil, p.rType.String(), "nil")
+	}
+	rValue := reflect.ValueOf(data)
+	rType := rValue.Type()
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType != p.rType {
+		return false, newInputTypeError(rValue.Type(), p.rType.String())
+	}
+	result, err := p.root(&planEnv{data: rValue})
+	return result == truthTrue, err
+}
+
+func (p *planCompiler) compile(condition *structs.Condition) (evaluator, error) {
+	var eval evaluator
+	var err error
+	switch {
+	case condition.Quantifier != "":
+		eval, err = p.compileQuantifier(condition)
+	case len(condition.Conditions) > 0:
+		eval, err = p.compileConditions(condition.Conditions)
+	default:
+		eval, err = p.compileComparison(condition)
+	}
+	if err != nil || !condition.Negate {
+		return eval, err
+	}
+	return func(env *planEnv) (truth, error) {
+		result, err := eval(env)
+		if err != nil {
+			return truthFalse, err
+		}
+		return result.not(), nil
+	}, nil
+}
+
+// compileConditions folds the conditions left to right like
+// validateConditions.
+func (p *planCompiler) compileConditions(conditions []*structs.Condition) (evaluator, error) {
+	evals := make([]evaluator, len(conditions))
+	logicalOperators := make([]string, len(conditions))
+	for i, subCondition := range conditions {
+		eval, err := p.compile(subCondition)
+		if err != nil {
+			return nil, err
+		}
+		evals[i] = eval
+		logicalOperators[i] = subCondition.Operator
+	}
+	return func(env *planEnv) (result truth, err error) {
+		for i, eval := range evals {
+			if i > 0 && result.isDecided(logicalOperators[i]) {
+				continue
+			}
+			next, err := eval(env)
+			if err != nil {
+				return truthFalse, err
+			}
+			if i == 0 {
+				result = next
+			} else {
+				result = result.join(logicalOperators[i], next)
+			}
+		}
+		return
+	}, nil
+}
+
+func (p *planCompiler) compileQuantifier(condition *structs.Condition) (evaluator, error) {
+	quantifier := condition.Quantifier
+	switch quantifier {
+	case quantifiers.QuantifierAny, quantifiers.QuantifierAll, quantifiers.QuantifierNone:
+	default:
+		return nil, newInvalidParameterError("quantifier any, all or none")
+	}
+	collection, collectionType := p.compilePath(condition.Collection)
+	var elementType reflect.Type
+	for collectionType != nil && collectionType.Kind() == reflect.Ptr {
+		collectionType = collectionType.Elem()
+	}
+	if collectionType != nil && (collectionType.Kind() == reflect.Slice || collectionType.Kind() == reflect.Array) {
+		elementType = collectionType.Elem()
+	}
+
+	depth := len(p.scopes)
+	p.scopes = append(p.scopes, planScope{
+		path:  condition.Collection,
+		depth: depth,
+		rType: elementType,
+	})
+	eval, err := p.compileConditions(condition.Conditions)
+	p.scopes = p.scopes[:depth]
+	if err != nil {
+		return nil, err
+	}
+
+	con := p.child(condition)
+	return func(env *planEnv) (truth, error) {
+		value, status := collection(env)
+		if status == pathFound {
+			value, status = indirect(value)
+		}
+		if status != pathFound {
+			if con.nullPolicy == nullpolicies.ZeroValue {
+				return toTruth(quantifier != quantifiers.QuantifierAny), nil
+			}
+			return con.nullResult(con.Collection, status == pathNull)
+		}
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return truthFalse, nil
+		}
+		isUnknown := false
+		for i := 0; i < value.Len(); i++ {
+			env.elements = append(env.elements[:depth], value.Index(i))
+			elementResult, err := eval(env)
+			if err != nil {
+				return truthFalse, err
+			}
+			switch {
+			case elementResult == truthUnknown:
+				isUnknown = true
+			case quantifier == quantifiers.QuantifierAny && elementResult == truthTrue:
+				return truthTrue, nil
+			case quantifier == quantifiers.QuantifierAll && elementResult == truthFalse,
+				quantifier == quantifiers.QuantifierNone && elementResult == truthTrue:
+				return truthFalse, nil
+			}
+		}
+		if isUnknown {
+			return truthUnknown, nil
+		}
+		return toTruth(quantifier != quantifiers.QuantifierAny), nil
+	}, nil
+}
+
+func (p *planCompiler) compileComparison(condition *structs.Condition) (evaluator, error) {
+	con := p.child(condition)
+	if con.Attribute == nil {
+		return func(env *planEnv) (truth, error) {
+			return truthFalse, nil
+		}, nil
+	}
+	switch con.Attribute.Function {
+	case "", functions.FunctionLen:
+	default:
+		return nil, newInvalidParameterError("function len")
+	}
+	attribute, _ := p.compilePath(con.Attribute.Name)
+	var reference accessor
+	if con.Attribute.Type == valuetypes.Field {
+		reference, _ = p.compilePath(con.Attribute.Value)
+	}
+	isMap := p.isMap
+
+	return func(env *planEnv) (truth, error) {
+		if isMap {
+			data, status := indirect(env.data)
+			if status != pathFound {
+				return truthFalse, nil
+			}
+			if data.Len() == 0 {
+				return truthFalse, newNilDataError(data.Type(), "non-empty map", "nil")
+			}
+		}
+		value, status := attribute(env)
+		if reference == nil {
+			return con.validateValue(value, status, nil)
+		}
+		return con.validateValue(value, status, func() (reflect.Value, pathStatus) {
+			return reference(env)
+		})
+	}, nil
+}
+
+// compilePath compiles the resolving of path the way resolve does it, also
+// returning the static type of the value when it's known.
+func (p *planCompiler) compilePath(path string) (accessor, reflect.Type) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		s := p.scopes[i]
+		element := func(env *planEnv) (reflect.Value, pathStatus) {
+			return env.elements[s.depth], pathFound
+		}
+		if path == s.path {
+			return element, s.rType
+		}
+		if strings.HasPrefix(path, s.path) && path[len(s.path)] == '.' {
+			return p.compileChild(element, s.rType, path[len(s.path)+1:])
+		}
+	}
+	data := func(env *planEnv) (reflect.Value, pathStatus) {
+		return env.data, pathFound
+	}
+	value, rType := p.compileChild(data, p.rType, path)
+	i := strings.IndexByte(path, '.')
+	if !p.removePrefix || i <= 0 {
+		return value, rType
+	}
+	retry, retryType := p.compileChild(data, p.rType, path[i+1:])
+	if retryType != rType {
+		rType = nil
+	}
+	return func(env *planEnv) (reflect.Value, pathStatus) {
+		result, status := value(env)
+		if status == pathMissing {
+			return retry(env)
+		}
+		return result, status
+	}, rType
+}
+
+// compileChild compiles resolvePath for the value parent resolves to. Struct
+// fields are looked up at compile time, maps, slices and interfaces are left
+// to resolvePath at run time.
+func (p *planCompiler) compileChild(parent accessor, rType reflect.Type, path string) (accessor, reflect.Type) {
+	for rType != nil && rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType == nil || rType.Kind() != reflect.Struct {
+		return func(env *planEnv) (reflect.Value, pathStatus) {
+			value, status := parent(env)
+			if status != pathFound {
+				return value, status
+			}
+			return p.resolvePath(value, path)
+		}, nil
+	}
+
+	indexes := p.fieldIndexes.get(rType)
+	if index, ok := indexes[path]; ok {
+		return fieldAccessor(parent, index), rType.FieldByIndex(index).Type
+	}
+	for i := strings.LastIndexByte(path, '.'); i > 0; i = strings.LastIndexByte(path[:i], '.') {
+		if index, ok := indexes[path[:i]]; ok {
+			return p.compileChild(fieldAccessor(parent, index), rType.FieldByIndex(index).Type, path[i+1:])
+		}
+	}
+	return func(env *planEnv) (reflect.Value, pathStatus) {
+		value, status := parent(env)
+		if status != pathFound {
+			return value, status
+		}
+		if value, status = indirect(value); status != pathFound {
+			return value, status
+		}
+		return reflect.Value{}, pathMissing
+	}, nil
+}
+
+// fieldAccessor walks the field index like lookupChild, dereferencing the
+// embedded struct pointers on the way.
+func fieldAccessor(parent accessor, index []int) accessor {
+	return func(env *planEnv) (reflect.Value, pathStatus) {
+		value, status := parent(env)
+		if status != pathFound {
+			return value, status
+		}
+		for _, x := range index {
+			if value, status = indirect(value); status != pathFound {
+				return value, status
+			}
+			value = value.Field(x)
+		}
+		return value, pathFound
+	}
+}
@@ -0,0 +1,394 @@
+package validators
+
+import (
+	"fmt"
+	"github.com/ahmadrezamusthafa/deep-validator/consts/sort-directions"
+	"github.com/ahmadrezamusthafa/deep-validator/structs"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type sortKey struct {
+	name       string
+	descending bool
+}
+
+// Query filters the items of the data slice with the condition and the
+// conditions of base, sorts them by the footer, projects the fields of base
+// and pages the result. The result is a slice of the type of data, or a
+// []map[string]interface{} keyed by field name when base has fields.
+func (c *Condition) Query(data interface{}, base structs.BaseCondition) (result interface{}, err error) {
+	if data == nil {
+		return nil, newNilDataError(nil, "slice", "nil")
+	}
+	rValue := reflect.ValueOf(data)
+	if rValue.Kind() != reflect.Slice && rValue.Kind() != reflect.Array {
+		return nil, newInputTypeError(rValue.Type(), "slice")
+	}
+	keys, err := getSortKeys(base.Footer)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []*Condition
+	if !c.Condition.IsEmpty() {
+		filters = append(filters, c)
+	}
+	if len(base.Conditions) > 0 {
+		filter := c.child(&structs.Condition{Conditions: base.Conditions})
+		filter.comparisons = make(map[*structs.Attribute]*comparison)
+		buildComparisons(filter.Condition, filter.comparisons)
+		filters = append(filters, filter)
+	}
+
+	items := make([]reflect.Value, 0, rValue.Len())
+	for i := 0; i < rValue.Len(); i++ {
+		isValid := true
+		for _, filter := range filters {
+			if isValid, err = filter.Validate(rValue.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+			if !isValid {
+				break
+			}
+		}
+		if isValid {
+			items = append(items, rValue.Index(i))
+		}
+	}
+
+	if len(keys) > 0 {
+		items = c.sortItems(items, keys)
+	}
+	if len(base.Fields) == 0 {
+		if base.Distinct {
+			items = distinctItems(items)
+		}
+		items = paginate(items, base.Footer)
+		rSlice := reflect.MakeSlice(reflect.SliceOf(rValue.Type().Elem()), 0, len(items))
+		for _, item := range items {
+			rSlice = reflect.Append(rSlice, item)
+		}
+		return rSlice.Interface(), nil
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		row := make(map[string]interface{}, len(base.Fields))
+		rowKey := make([]string, len(base.Fields))
+		for i, field := range base.Fields {
+			value, ok := c.resolveValue(item, field)
+			if !ok {
+				row[field] = nil
+				rowKey[i] = "\x00"
+				continue
+			}
+			row[field] = value.Interface()
+			rowKey[i], _ = formatValue(value)
+		}
+		if base.Distinct {
+			key := strings.Join(rowKey, "\x1f")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		rows = append(rows, row)
+	}
+	return paginate(rows, base.Footer), nil
+}
+
+// getSortKeys orders the sort keys of footer by its SortKeys, the other keys
+// following in alphabetical order.
+func getSortKeys(footer structs.Footer) ([]sortKey, error) {
+	names := make([]string, 0, len(footer.Sort)+len(footer.SortKeys))
+	listed := make(map[string]bool, len(footer.SortKeys))
+	for _, name := range footer.SortKeys {
+		if !listed[name] {
+			listed[name] = true
+			names = append(names, name)
+		}
+	}
+	var others []string
+	for name := range footer.Sort {
+		if !listed[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	keys := make([]sortKey, len(names))
+	for i, name := range names {
+		switch direction := strings.ToLower(footer.Sort[name]); direction {
+		case "", sortdirections.SortDirectionAsc:
+			keys[i] = sortKey{name: name}
+		case sortdirections.SortDirectionDesc:
+			keys[i] = sortKey{name: name, descending: true}
+		default:
+			return nil, newInvalidParameterError("sort direction asc or desc")
+		}
+	}
+	return keys, nil
+}
+
+// resolveValue resolves path in item, ok is false when it's missing or nil.
+func (c *Condition) resolveValue(item reflect.Value, path string) (value reflect.Value, ok bool) {
+	value, status := c.resolve(item, path)
+	if status != pathFound {
+		return value, false
+	}
+	if value, status = indirect(value); status != pathFound {
+		return value, false
+	}
+	return value, true
+}
+
+// sortItems sorts the items stably by the keys, comparing the values as
+// coerce converts them. Missing and null values come last whatever the
+// direction.
+func (c *Condition) sortItems(items []reflect.Value, keys []sortKey) []reflect.Value {
+	type sortable struct {
+		item   reflect.Value
+		values []interface{}
+	}
+	sortables := make([]sortable, len(items))
+	for i, item := range items {
+		sortables[i] = sortable{
+			item:   item,
+			values: make([]interface{}, len(keys)),
+		}
+		for k, key := range keys {
+			if value, ok := c.resolveValue(item, key.name); ok {
+				sortables[i].values[k], _ = coerce(value)
+			}
+		}
+	}
+	sort.SliceStable(sortables, func(i, j int) bool {
+		for k, key := range keys {
+			first, second := sortables[i].values[k], sortables[j].values[k]
+			switch {
+			case first == nil && second == nil:
+				continue
+			case first == nil:
+				return false
+			case second == nil:
+				return true
+			}
+			result := compareSortValues(first, second)
+			if result == 0 {
+				continue
+			}
+			return (result < 0) != key.descending
+		}
+		return false
+	})
+	for i := range sortables {
+		items[i] = sortables[i].item
+	}
+	return items
+}
+
+// compareSortValues compares two values converted by coerce: numbers exactly
+// by value whatever their types, strings and bools by their natural order and
+// times chronologically. Values of different kinds are ordered by kind:
+// numbers, strings, times, then bools.
+func compareSortValues(first, second interface{}) int {
+	firstRank, secondRank := sortRank(first), sortRank(second)
+	if firstRank != secondRank {
+		return firstRank - secondRank
+	}
+	switch firstRank {
+	case 0:
+		return compareNumbers(first, second)
+	case 1:
+		return strings.Compare(first.(string), second.(string))
+	case 2:
+		firstTime, secondTime := first.(time.Time), second.(time.Time)
+		switch {
+		case firstTime.Before(secondTime):
+			return -1
+		case firstTime.After(secondTime):
+			return 1
+		}
+		return 0
+	}
+	switch firstBool, secondBool := first.(bool), second.(bool); {
+	case firstBool == secondBool:
+		return 0
+	case secondBool:
+		return -1
+	}
+	return 1
+}
+
+func sortRank(value interface{}) int {
+	switch value.(type) {
+	case int64, uint64, float32, float64, *big.Rat:
+		return 0
+	case string:
+		return 1
+	case time.Time:
+		return 2
+	}
+	return 3
+}
+
+// compareNumbers compares two numbers converted by coerce exactly, NaN before
+// every other number.
+func compareNumbers(first, second interface{}) int {
+	switch firstNumber := first.(type) {
+	case int64:
+		if secondNumber, ok := second.(int64); ok {
+			return compareOrdered(firstNumber, secondNumber)
+		}
+	case uint64:
+		if secondNumber, ok := second.(uint64); ok {
+			return compareOrdered(firstNumber, secondNumber)
+		}
+	}
+	firstRat, firstRank := exactNumber(first)
+	secondRat, secondRank := exactNumber(second)
+	if firstRank != 0 || secondRank != 0 {
+		return firstRank - secondRank
+	}
+	return firstRat.Cmp(secondRat)
+}
+
+// exactNumber returns number as an exact *big.Rat. A float that has none is
+// ranked instead: -2 for NaN, -1 for -Inf and 1 for +Inf, 0 is a finite
+// number.
+func exactNumber(number interface{}) (*big.Rat, int) {
+	var float float64
+	switch val := number.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(val), 0
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(val)), 0
+	case *big.Rat:
+		return val, 0
+	case float32:
+		float = float64(val)
+	case float64:
+		float = val
+	}
+	switch {
+	case math.IsNaN(float):
+		return nil, -2
+	case math.IsInf(float, -1):
+		return nil, -1
+	case math.IsInf(float, 1):
+		return nil, 1
+	}
+	return new(big.Rat).SetFloat64(float), 0
+}
+
+func compareOrdered[T int64 | uint64](first, second T) int {
+	switch {
+	case first < second:
+		return -1
+	case first > second:
+		return 1
+	}
+	return 0
+}
+
+// distinctItems keeps the first of the deeply equal items. Items are
+// bucketed by their distinctKey, so only the items of a bucket are compared.
+func distinctItems(items []reflect.Value) []reflect.Value {
+	result := make([]reflect.Value, 0, len(items))
+	buckets := make(map[string][]interface{}, len(items))
+	for _, item := range items {
+		key, value := distinctKey(item), item.Interface()
+		isDuplicate := false
+		for _, kept := range buckets[key] {
+			if reflect.DeepEqual(value, kept) {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			buckets[key] = append(buckets[key], value)
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// distinctKey formats the coerced values of the scalar fields of a struct
+// item, or of the item itself when it is a scalar. Deeply equal items have
+// the same key, items with the same key aren't always equal.
+func distinctKey(item reflect.Value) string {
+	item, status := indirect(item)
+	if status != pathFound {
+		return "\x00"
+	}
+	if item.Kind() != reflect.Struct || item.Type() == timeType {
+		return scalarKey(item)
+	}
+	fields := make([]string, item.NumField())
+	for i := range fields {
+		if field := item.Field(i); field.CanInterface() {
+			fields[i] = scalarKey(field)
+		}
+	}
+	return strings.Join(fields, "\x1f")
+}
+
+// scalarKey formats a value of a scalar kind or a time with its coerced type,
+// other values give an empty key.
+func scalarKey(value reflect.Value) string {
+	value, status := indirect(value)
+	if status != pathFound {
+		return "\x00"
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+	default:
+		if value.Type() != timeType {
+			return ""
+		}
+	}
+	converted, err := coerce(value)
+	if err != nil {
+		return ""
+	}
+	switch val := converted.(type) {
+	case time.Time:
+		return strconv.FormatInt(val.UnixNano(), 10)
+	case float32:
+		// -0 and 0 are deeply equal
+		converted = val + 0
+	case float64:
+		converted = val + 0
+	}
+	return fmt.Sprintf("%T:%v", converted, converted)
+}
+
+// paginate skips Offset items and returns the page of the rest, the first
+// page when Page is below 1 and every item when Limit isn't set.
+func paginate[T any](items []T, footer structs.Footer) []T {
+	start := 0
+	if footer.Offset > 0 {
+		start = footer.Offset
+	}
+	if footer.Limit > 0 && footer.Page > 1 {
+		start += (footer.Page - 1) * footer.Limit
+	}
+	if start >= len(items) {
+		return items[:0]
+	}
+	end := len(items)
+	if footer.Limit > 0 && start+footer.Limit < end {
+		end = start + footer.Limit
+	}
+	return items[start:end]
+}
@@ -0,0 +1,94 @@
+package validators
+
+import (
+	"github.com/ahmadrezamusthafa/deep-validator/common/utils"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueSet holds the members of an in / not in list parsed up front for every
+// type a field can be compared as, so membership is a map lookup whatever the
+// size of the list.
+type valueSet struct {
+	strings       map[string]struct{}
+	foldedStrings map[string]struct{}
+	ints          map[int64]struct{}
+	uints         map[uint64]struct{}
+	floats        map[float64]struct{}
+	floats32      map[float32]struct{}
+	times         map[int64]struct{}
+	bools         map[bool]struct{}
+	decimals      map[string]struct{}
+}
+
+func newValueSet(values []string) *valueSet {
+	set := &valueSet{
+		strings:       make(map[string]struct{}, len(values)),
+		foldedStrings: make(map[string]struct{}, len(values)),
+		ints:          make(map[int64]struct{}),
+		uints:         make(map[uint64]struct{}),
+		floats:        make(map[float64]struct{}),
+		floats32:      make(map[float32]struct{}),
+		times:         make(map[int64]struct{}),
+		bools:         make(map[bool]struct{}),
+		decimals:      make(map[string]struct{}),
+	}
+	for _, value := range values {
+		set.strings[value] = struct{}{}
+		set.foldedStrings[strings.ToLower(value)] = struct{}{}
+		set.bools[utils.StringToBool(value)] = struct{}{}
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			set.ints[intValue] = struct{}{}
+		}
+		if uintValue, err := strconv.ParseUint(value, 10, 64); err == nil {
+			set.uints[uintValue] = struct{}{}
+		}
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			set.floats[floatValue] = struct{}{}
+		}
+		if floatValue, err := strconv.ParseFloat(value, 32); err == nil {
+			set.floats32[float32(floatValue)] = struct{}{}
+		}
+		if timeValue, err := time.Parse(time.RFC3339, value); err == nil {
+			set.times[timeValue.UnixNano()] = struct{}{}
+		}
+		if decimal, err := parseDecimal(value); err == nil {
+			set.decimals[decimal.RatString()] = struct{}{}
+		}
+	}
+	return set
+}
+
+// contains looks value up in the member set matching its type, a value
+// converted the way coerce converts field values. Members that can't be
+// parsed as that type never match.
+func (s *valueSet) contains(value interface{}) (ok bool) {
+	switch val := value.(type) {
+	case int64:
+		_, ok = s.ints[val]
+	case uint64:
+		_, ok = s.uints[val]
+	case float64:
+		_, ok = s.floats[val]
+	case float32:
+		_, ok = s.floats32[val]
+	case *big.Rat:
+		_, ok = s.decimals[val.RatString()]
+	case time.Time:
+		_, ok = s.times[val.UnixNano()]
+	case bool:
+		_, ok = s.bools[val]
+	case string:
+		_, ok = s.strings[val]
+	}
+	return
+}
+
+// containsFold looks value up ignoring case, the way condition values are
+// compared.
+func (s *valueSet) containsFold(value string) bool {
+	_, ok := s.foldedStrings[strings.ToLower(value)]
+	return ok
+}